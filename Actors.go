@@ -0,0 +1,161 @@
+package alien_invastion
+
+import "sync"
+
+// RunActors plays the game to the end like calling Update until it returns false, but every alien is a goroutine of its own,
+// and every city aliens move from or to is served by a goroutine as well. A city owns its state : in every tick it lends
+// itself to the moves reaching it, one after another in the order Update moves them, and an alien moves once all
+// cities its move reaches are lent to it. Aliens far from each other move at the same time, and the tick ends at a barrier
+// waiting for all of them, where events and aliens on roads are put back in the order of Update, so the game plays
+// exactly like with Update for the same seed. Turns of Player units, and turns from a unit that may go more than max steps
+// on, are played by the goroutine calling RunActors. Goroutines of dead aliens and of cities left alone end with the tick,
+// the others with the game. Workers are ignored.
+func (m *GameMap) RunActors() {
+	m.stage = newStage()
+	defer func() {
+		m.stage.close()
+		m.stage = nil
+	}()
+	for m.Update() {
+	}
+}
+
+// stage holds goroutines of aliens and cities while playing with RunActors
+type stage struct {
+	aliens map[*Alien]chan *move
+	cities map[*City]chan []*move
+	done   chan struct{} // A move is over, back to the barrier
+	wg     sync.WaitGroup
+}
+
+// move is the turn of an alien in a tick, played on the goroutine of the alien once cities it reaches are lent to it
+type move struct {
+	turn
+	reach  []*City
+	shard  *shard        // Keeps what the move changes outside of its cities, for the barrier
+	lent   chan struct{} // A city of the move is lent to it
+	played chan struct{} // Closed once the move is over, cities get back their state
+}
+
+func newStage() *stage {
+	return &stage{
+		aliens: make(map[*Alien]chan *move),
+		cities: make(map[*City]chan []*move),
+		done:   make(chan struct{}),
+	}
+}
+
+// round moves units of the turns on their goroutines, and retires goroutines no longer needed once all of them moved.
+// willContinue is false if a unit went more than max steps, units after it don't move, like in Update.
+func (s *stage) round(m *GameMap, turns []turn) (willContinue bool) {
+	if played(turns) {
+		return m.playTurns(turns)
+	}
+	cut := m.firstExceeding(turns)
+	var moves []*move
+	queues := make(map[*City][]*move)
+	for i, t := range turns[:cut] {
+		if !t.alien.Alive {
+			continue
+		}
+		mv := &move{turn: t, shard: &shard{dice: newDice(), turn: i}, played: make(chan struct{})}
+		reaches(t.city, func(city *City) {
+			// Several roads may lead to the same city
+			if queue := queues[city]; len(queue) == 0 || queue[len(queue)-1] != mv {
+				mv.reach = append(mv.reach, city)
+				queues[city] = append(queue, mv)
+			}
+		})
+		mv.lent = make(chan struct{}, len(mv.reach))
+		moves = append(moves, mv)
+	}
+	for city, queue := range queues {
+		s.city(city) <- queue
+	}
+	for _, mv := range moves {
+		s.alien(mv.alien) <- mv
+	}
+	shards := make([]*shard, len(moves))
+	for i, mv := range moves {
+		<-s.done
+		shards[i] = mv.shard
+	}
+	m.apply(shards)
+	willContinue = m.playTurns(turns[cut:])
+
+	for alien, inbox := range s.aliens {
+		if !alien.Alive {
+			close(inbox)
+			delete(s.aliens, alien)
+		}
+	}
+	for city, inbox := range s.cities {
+		if len(city.Aliens) == 0 {
+			close(inbox)
+			delete(s.cities, city)
+		}
+	}
+	return willContinue
+}
+
+// alien returns the channel of moves of the alien, and starts its goroutine first if it has none
+func (s *stage) alien(alien *Alien) chan *move {
+	if moves, found := s.aliens[alien]; found {
+		return moves
+	}
+	moves := make(chan *move)
+	s.aliens[alien] = moves
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for mv := range moves {
+			for range mv.reach {
+				<-mv.lent
+			}
+			for _, city := range mv.reach {
+				city.shard = mv.shard
+			}
+			if mv.alien.Alive {
+				mv.shard.dice.source.Seed(mv.seed)
+				mv.alien.Move(mv.city)
+			}
+			for _, city := range mv.reach {
+				city.shard = nil
+			}
+			close(mv.played)
+			s.done <- struct{}{}
+		}
+	}()
+	return moves
+}
+
+// city returns the channel of moves reaching the city in every tick, and starts its goroutine first if it has none
+func (s *stage) city(city *City) chan []*move {
+	if queues, found := s.cities[city]; found {
+		return queues
+	}
+	queues := make(chan []*move)
+	s.cities[city] = queues
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for queue := range queues {
+			for _, mv := range queue {
+				mv.lent <- struct{}{}
+				<-mv.played
+			}
+		}
+	}()
+	return queues
+}
+
+// close ends all goroutines, and waits for them
+func (s *stage) close() {
+	for _, moves := range s.aliens {
+		close(moves)
+	}
+	for _, queues := range s.cities {
+		close(queues)
+	}
+	s.wg.Wait()
+}
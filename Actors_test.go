@@ -0,0 +1,179 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGameMap_RunActors(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func() *GameMap
+		aliens int
+	}{
+		{
+			name: "Sample map",
+			build: func() *GameMap {
+				m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+				return m
+			},
+			aliens: 6,
+		},
+		{
+			name: "Graph map",
+			build: func() *GameMap {
+				m, _ := (&StreamParser{Graph: true}).ParseFile("test_resources/graph_map.txt")
+				return m
+			},
+			aliens: 3,
+		},
+		{
+			name: "Long and one-way roads",
+			build: func() *GameMap {
+				m, _ := (&StreamParser{}).ParseString("A east=B:3 south=C\nB south=D\nC east=D:2\nD east=>E\nE south=F:4\nF west=G\nG north=>D")
+				return m
+			},
+			aliens: 4,
+		},
+		{
+			name: "Grid map with defenders and waves",
+			build: func() *GameMap {
+				m, _ := NewGridGameMap(30, 20)
+				m.SetSeed(3)
+				defenders := []*Alien{m.AlienIDs().NewDefender(Hunt{}), m.AlienIDs().NewDefender(Guard{})}
+				for i, defender := range defenders {
+					defender.Number = 1000 + i
+				}
+				_ = m.AssignAliens(defenders)
+				landed := 2000
+				_ = m.SetSpawnSchedule([]Wave{{Tick: 5, Count: 10, Every: 20, Times: 3}}, func() *Alien {
+					landed++
+					return &Alien{Number: landed, Alive: true, Strength: 1, Health: 1}
+				})
+				return m
+			},
+			aliens: 100,
+		},
+		{
+			name: "Compact grid map",
+			build: func() *GameMap {
+				m, _ := NewCompactGridGameMap(30, 20)
+				return m
+			},
+			aliens: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.build()
+			m.SetCombatResolver(StrengthResolver{RetreatChance: 0.2})
+			wantEvents, wantDump, wantStats := play(m, tt.aliens)
+			assert.NotEmpty(t, wantEvents)
+
+			m = tt.build()
+			m.SetCombatResolver(StrengthResolver{RetreatChance: 0.2})
+			events, dump, stats := playWith(m, tt.aliens, (*GameMap).RunActors)
+			assert.Equal(t, wantEvents, events)
+			assert.Equal(t, wantDump, dump)
+			assert.Equal(t, wantStats, stats)
+			assert.Nil(t, m.stage)
+		})
+	}
+}
+
+func TestGameMap_RunActorsEndsGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	m, _ := NewGridGameMap(10, 10)
+	m.SetSeed(0)
+	m.SetMaxTicks(20)
+	m.SetCityCapacity(0)
+	aliens := make([]*Alien, 10)
+	for i := range aliens {
+		aliens[i] = m.AlienIDs().NewAlien()
+		aliens[i].Faction = "red"
+	}
+	_ = m.AssignAliens(aliens)
+	m.RunActors()
+	// Goroutines may take a moment to exit once they are done
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, before, runtime.NumGoroutine())
+}
+
+// overlapping is a random walk counting moves made at the same time
+type overlapping struct {
+	active, most *int32
+}
+
+func (s overlapping) Next(rng *rand.Rand, unit *Alien, from *City, exits []Exit) (Exit, bool) {
+	active := atomic.AddInt32(s.active, 1)
+	defer atomic.AddInt32(s.active, -1)
+	for {
+		most := atomic.LoadInt32(s.most)
+		if active <= most || atomic.CompareAndSwapInt32(s.most, most, active) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return exits[rng.Intn(len(exits))], true
+}
+
+func TestGameMap_RunActorsConcurrently(t *testing.T) {
+	var active, most int32
+	build := func() *GameMap {
+		m, _ := NewGridGameMap(20, 20)
+		m.SetSeed(0)
+		m.SetMaxTicks(10)
+		aliens := make([]*Alien, 20)
+		for i := range aliens {
+			aliens[i] = m.AlienIDs().NewAlien()
+			aliens[i].Faction = "red"
+			aliens[i].Strategy = overlapping{active: &active, most: &most}
+		}
+		_ = m.AssignAliens(aliens)
+		return m
+	}
+	m := build()
+	for m.Update() {
+	}
+	assert.Equal(t, int32(1), most)
+
+	// Aliens far from each other move at the same time, and the game plays the same
+	actors := build()
+	actors.RunActors()
+	assert.Greater(t, most, int32(1))
+	assert.Equal(t, m.Events(), actors.Events())
+	assert.Equal(t, m.DumpMap(), actors.DumpMap())
+}
+
+func TestStage_round(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("A east=B\nB east=C\nC east=D")
+	m.SetSeed(0)
+	a, b, c := m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien()
+	c.Strategy = Guard{}
+	_ = m.PlaceAlien(a, "A")
+	_ = m.PlaceAlien(b, "B")
+	_ = m.PlaceAlien(c, "D")
+	s := newStage()
+	defer s.close()
+	turns := []turn{{alien: a, city: m.cities["A"]}, {alien: b, city: m.cities["B"]}, {alien: c, city: m.cities["D"]}}
+	m.SetMaxSteps(10)
+	assert.True(t, s.round(m, turns))
+	// a walked into b and both died, so their goroutines and the ones of their cities are retired
+	assert.False(t, a.Alive)
+	assert.False(t, b.Alive)
+	assert.Equal(t, map[*Alien]chan *move{c: s.aliens[c]}, s.aliens)
+	assert.Equal(t, map[*City]chan []*move{m.cities["D"]: s.cities[m.cities["D"]]}, s.cities)
+	for _, city := range m.Cities() {
+		assert.Nil(t, city.shard)
+	}
+
+	m.SetMaxSteps(1)
+	assert.False(t, s.round(m, []turn{{alien: c, city: m.cities["D"]}}))
+	assert.Equal(t, 2, c.Steps)
+}
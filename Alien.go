@@ -1,50 +1,61 @@
 package alien_invastion
 
-import "math/rand"
-
-var alienSerialCount = 0
-
 // Alien is a struct that represents an alien. Alien always attached in struct City
 type Alien struct {
-	Number int
-	Steps  int
-	Alive  bool
+	Number   int
+	Steps    int
+	Alive    bool
+	Strength int    // How hard the alien fights, also damage dealt to the city (at least 1)
+	Health   int    // Alien dies when health drops to 0
+	Faction  string // Empty if the alien belongs to no faction
+	Side     Side
+	Strategy MovementStrategy // How the alien moves, RandomWalk if nil
+	Fatigue  int              // Tiredness of moves, see FatigueRules
 }
 
+// NewAlien creates an alien numbered by the package-wide numbering, safe for concurrent use, see DefaultAlienIDs.
+// Simulations should number their units with GameMap.AlienIDs instead, so they don't share numbers.
 func NewAlien() *Alien {
-	defer func() {
-		alienSerialCount++
-	}()
-	return &Alien{Number: alienSerialCount, Steps: 0, Alive: true}
+	return defaultAlienIDs.NewAlien()
 }
 
-// Move is a method that moves alien to a random neighbor city
+// Move is a method that moves alien to a neighbor city picked by its strategy, a random one by default
 // All battle result against two aliens is done in City.AlienMigrate
+// If the road takes more than one tick, the alien is in transit and `to` is the city it will arrive
 func (a *Alien) Move(from *City) (to *City, step int) {
 	a.Steps++
-	if a.Alive == false {
+	if a.Alive == false || a.aged(from) {
 		return from, a.Steps
 	}
 
 	if from.IsIsolatedOrDestroyed() {
 		//Still alive, but no longer able to move
+		a.rest(from)
+		return from, a.Steps
+	}
+	if a.tired(from) {
 		return from, a.Steps
 	}
 
 	// Let's pick one of the cities that are not destroyed
-	var candidates []*City
-	for _, city := range from.Neighborhoods {
-		if city == nil {
-			continue
-		}
-		if city.Exists {
-			candidates = append(candidates, city)
+	var candidates []Exit
+	for _, exit := range from.Exits() {
+		if exit.To.Exists {
+			candidates = append(candidates, exit)
 		}
 	}
 
-	// randomly pick one of the candidates
-	var index = rand.Intn(len(candidates))
-	from.AlienMigrate(candidates[index])
+	exit, move := a.strategy().Next(from.random(), a, from, candidates)
+	if !move {
+		a.rest(from)
+		return from, a.Steps
+	}
+	a.tire(from)
+	if exit.Road.TravelTime() > 1 && from.gameMap != nil {
+		from.gameMap.depart(a, from, exit)
+	} else {
+		from.AlienMigrate(a, exit.To)
+	}
 
-	return candidates[index], a.Steps
+	return exit.To, a.Steps
 }
@@ -0,0 +1,106 @@
+package alien_invastion
+
+import (
+	"strconv"
+	"sync"
+)
+
+// AlienIDs numbers units of a simulation one after another, from 0 unless reset, and names them in events.
+// It is safe for concurrent use, and every map has its own, see GameMap.AlienIDs, so simulations running side by side
+// never share numbers.
+type AlienIDs struct {
+	mu     sync.Mutex
+	next   int
+	naming func(number int) string
+}
+
+// defaultAlienIDs numbers units created by NewAlien and NewDefender
+var defaultAlienIDs = &AlienIDs{}
+
+// DefaultAlienIDs returns the package-wide numbering of NewAlien and NewDefender, Reset it to number units from 0 again
+func DefaultAlienIDs() *AlienIDs {
+	return defaultAlienIDs
+}
+
+// Next returns the number of the next unit
+func (ids *AlienIDs) Next() int {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	number := ids.next
+	ids.next++
+	return number
+}
+
+// Reset numbers next units from given number
+func (ids *AlienIDs) Reset(next int) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	ids.next = next
+}
+
+// SetNaming names units in events by their numbers, nil to name them by their numbers only, see NameList
+func (ids *AlienIDs) SetNaming(naming func(number int) string) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	ids.naming = naming
+}
+
+// Name returns the name of the unit of given number, the number itself unless named otherwise
+func (ids *AlienIDs) Name(number int) string {
+	ids.mu.Lock()
+	naming := ids.naming
+	ids.mu.Unlock()
+	if naming != nil {
+		if name := naming(number); name != "" {
+			return name
+		}
+	}
+	return strconv.Itoa(number)
+}
+
+// names returns names of units of given numbers, nil if units are named by their numbers
+func (ids *AlienIDs) names(numbers []int) []string {
+	ids.mu.Lock()
+	naming := ids.naming
+	ids.mu.Unlock()
+	if naming == nil || len(numbers) == 0 {
+		return nil
+	}
+	names := make([]string, len(numbers))
+	for i, number := range numbers {
+		names[i] = ids.Name(number)
+	}
+	return names
+}
+
+// NewAlien creates an alien with the next number
+func (ids *AlienIDs) NewAlien() *Alien {
+	return &Alien{Number: ids.Next(), Steps: 0, Alive: true, Strength: 1, Health: 1}
+}
+
+// NewDefender creates a defender with the next number, see NewDefender
+func (ids *AlienIDs) NewDefender(strategy MovementStrategy) *Alien {
+	defender := ids.NewAlien()
+	defender.Side = Defenders
+	defender.Strategy = strategy
+	return defender
+}
+
+// NameList names units by their numbers from the list, unit 0 gets the first name. Units beyond the list go by their numbers.
+func NameList(names []string) func(number int) string {
+	return func(number int) string {
+		if number >= 0 && number < len(names) {
+			return names[number]
+		}
+		return ""
+	}
+}
+
+// AlienIDs returns numbering of units of the simulation. Units created by the map, by a Game, and landing with waves
+// without their own constructor are numbered by it.
+func (m *GameMap) AlienIDs() *AlienIDs {
+	if m.ids == nil {
+		m.ids = &AlienIDs{}
+	}
+	return m.ids
+}
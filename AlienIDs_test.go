@@ -0,0 +1,101 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestAlienIDs_Next(t *testing.T) {
+	ids := &AlienIDs{}
+	assert.Equal(t, 0, ids.Next())
+	assert.Equal(t, 1, ids.NewAlien().Number)
+	defender := ids.NewDefender(Guard{})
+	assert.Equal(t, 2, defender.Number)
+	assert.Equal(t, Defenders, defender.Side)
+	ids.Reset(100)
+	assert.Equal(t, 100, ids.Next())
+	ids.Reset(0)
+	assert.Equal(t, 0, ids.Next())
+}
+
+func TestAlienIDs_NextConcurrently(t *testing.T) {
+	ids := &AlienIDs{}
+	numbers := make([][]int, 8)
+	var wg sync.WaitGroup
+	for i := range numbers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				numbers[i] = append(numbers[i], ids.NewAlien().Number)
+			}
+		}(i)
+	}
+	wg.Wait()
+	seen := make(map[int]bool)
+	for _, list := range numbers {
+		for _, number := range list {
+			assert.False(t, seen[number], number)
+			seen[number] = true
+		}
+	}
+	assert.Equal(t, 8000, len(seen))
+	assert.Equal(t, 8000, ids.Next())
+}
+
+func TestAlienIDs_Name(t *testing.T) {
+	tests := []struct {
+		name   string
+		naming func(number int) string
+		want   []string
+	}{
+		{name: "By numbers", want: []string{"0", "1", "2"}},
+		{name: "Name list", naming: NameList([]string{"Zorg", "Blip"}), want: []string{"Zorg", "Blip", "2"}},
+		{name: "Empty names", naming: NameList([]string{"", "Blip"}), want: []string{"0", "Blip", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := &AlienIDs{}
+			ids.SetNaming(tt.naming)
+			var got []string
+			for number := 0; number < 3; number++ {
+				got = append(got, ids.Name(number))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefaultAlienIDs(t *testing.T) {
+	DefaultAlienIDs().Reset(0)
+	defer DefaultAlienIDs().Reset(0)
+	assert.Equal(t, 0, NewAlien().Number)
+	assert.Equal(t, 1, NewDefender(Guard{}).Number)
+	DefaultAlienIDs().Reset(0)
+	assert.Equal(t, 0, NewAlien().Number)
+}
+
+func TestGameMap_AlienIDs(t *testing.T) {
+	// Every map numbers its own units
+	for i := 0; i < 2; i++ {
+		m, _ := (&StreamParser{}).ParseString("Foo north=Bar defenders=2\nBar")
+		defenders := m.PlaceDefenders(Guard{})
+		assert.Equal(t, []int{0, 1}, []int{defenders[0].Number, defenders[1].Number})
+		assert.Equal(t, 2, m.AlienIDs().NewAlien().Number)
+	}
+	assert.NotNil(t, (&GameMap{}).AlienIDs())
+}
+
+func TestGameMap_AlienIDsNaming(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar\nBar")
+	m.SetSeed(0)
+	m.AlienIDs().SetNaming(NameList([]string{"Zorg"}))
+	_ = m.PlaceAlien(m.AlienIDs().NewAlien(), "Foo")
+	_ = m.PlaceAlien(m.AlienIDs().NewAlien(), "Bar")
+	m.Update()
+	if assert.NotEmpty(t, m.Events()) {
+		assert.Equal(t, "City Bar have been destroyed by alien Zorg and 1!", m.Events()[0].String())
+		assert.Equal(t, []int{0, 1}, m.Events()[0].Aliens)
+	}
+}
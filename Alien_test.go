@@ -86,3 +86,28 @@ func TestAlien_Move(t *testing.T) {
 		})
 	}
 }
+
+func TestAlien_MoveOnGraphMap(t *testing.T) {
+	m := NewGraphGameMap()
+	for _, neighbor := range []string{"city2", "city3", "city4", "city5", "city6"} {
+		_ = m.ConnectCities("city1", "", neighbor)
+	}
+	m.cities["city2"].Exists = false
+	a := &Alien{Number: 0, Alive: true}
+	m.cities["city1"].Aliens = []*Alien{a}
+	gotTo, _ := a.Move(m.cities["city1"])
+	assert.Contains(t, []string{"city3", "city4", "city5", "city6"}, gotTo.Name)
+	assert.Equal(t, []*Alien{a}, gotTo.Aliens)
+}
+
+func TestAlien_MoveOnOneWayRoad(t *testing.T) {
+	m := NewGameMap()
+	_ = m.UpdateCityWithOneWayRoad("city1", North, "city2")
+	a := &Alien{Number: 0, Alive: true}
+	m.cities["city1"].Aliens = []*Alien{a}
+	gotTo, _ := a.Move(m.cities["city1"])
+	assert.Equal(t, "city2", gotTo.Name)
+	// No way back
+	gotTo, _ = a.Move(gotTo)
+	assert.Equal(t, "city2", gotTo.Name)
+}
@@ -0,0 +1,202 @@
+package alien_invastion
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BatchRun is the outcome of one game of a batch
+type BatchRun struct {
+	Seed            int64 `json:"seed"`
+	Ticks           int   `json:"ticks"`            // Ticks to the end of the game
+	CitiesDestroyed int   `json:"cities_destroyed"` // Cities destroyed at the end, rebuilt ones are not counted
+	Aliens          int   `json:"aliens"`           // Aliens took part, landed ones included
+	AliensDead      int   `json:"aliens_dead"`      // Aliens killed, exhausted, expired or merged into others
+}
+
+// CasualtyRate is the share of aliens dead at the end, 0 if there was no alien
+func (r BatchRun) CasualtyRate() float64 {
+	if r.Aliens == 0 {
+		return 0
+	}
+	return float64(r.AliensDead) / float64(r.Aliens)
+}
+
+// Distribution sums up a value over the games of a batch
+type Distribution struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"` // Median
+	P90  float64 `json:"p90"`
+}
+
+// newDistribution sums up values, nearest-rank percentiles
+func newDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	rank := func(p float64) float64 {
+		return sorted[int(math.Ceil(p*float64(len(sorted))))-1]
+	}
+	return Distribution{Min: sorted[0], Max: sorted[len(sorted)-1], Mean: sum / float64(len(sorted)), P50: rank(0.5), P90: rank(0.9)}
+}
+
+// BatchResult sums up the games of a batch
+type BatchResult struct {
+	Runs            []BatchRun         `json:"runs"`             // In order of seeds
+	Survival        map[string]float64 `json:"survival"`         // Share of games every city survives
+	DestroyedCities map[int]int        `json:"destroyed_cities"` // Games by number of cities destroyed
+	Ticks           Distribution       `json:"ticks"`
+	CasualtyRate    Distribution       `json:"casualty_rate"` // Share of aliens dead at the end of games
+	cities          []string           // Cities in order of the map
+}
+
+// RunBatch plays the scenario runs times on parallel goroutines, and sums up the games. Game i is seeded with the seed
+// of the scenario plus i, a random seed if not set, so the outcome doesn't depend on parallel. Output settings are ignored.
+func (s *Scenario) RunBatch(runs int, parallel int) (*BatchResult, error) {
+	if runs < 1 {
+		return nil, fmt.Errorf("at least 1 run is needed, got %d", runs)
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+	base := time.Now().UnixNano()
+	if s.Seed != nil {
+		base = *s.Seed
+	}
+
+	result := &BatchResult{Runs: make([]BatchRun, runs), Survival: make(map[string]float64), DestroyedCities: make(map[int]int)}
+	survived := make(map[string]int)
+	errs := make([]error, runs)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				run, cities, err := s.play(base + int64(i))
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				result.Runs[i] = run
+				mu.Lock()
+				if result.cities == nil {
+					result.cities = make([]string, len(cities))
+					for j, city := range cities {
+						result.cities[j] = city.Name
+					}
+				}
+				for _, city := range cities {
+					if city.Exists {
+						survived[city.Name]++
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < runs; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("seed %d: %s", base+int64(i), err)
+		}
+	}
+
+	ticks := make([]float64, runs)
+	casualties := make([]float64, runs)
+	for i, run := range result.Runs {
+		ticks[i] = float64(run.Ticks)
+		casualties[i] = run.CasualtyRate()
+		result.DestroyedCities[run.CitiesDestroyed]++
+	}
+	for _, name := range result.cities {
+		result.Survival[name] = float64(survived[name]) / float64(runs)
+	}
+	result.Ticks = newDistribution(ticks)
+	result.CasualtyRate = newDistribution(casualties)
+	return result, nil
+}
+
+// play plays the scenario to the end with given seed, and returns the outcome and cities of the map
+func (s *Scenario) play(seed int64) (BatchRun, []*City, error) {
+	scenario := *s
+	scenario.Seed = &seed
+	g, err := scenario.NewGame()
+	if err != nil {
+		return BatchRun{}, nil, err
+	}
+	g.Run()
+	cities := g.Map.Cities()
+	run := BatchRun{Seed: seed, Ticks: g.Map.Tick(), CitiesDestroyed: len(cities) - g.Map.ExistCityCount(), Aliens: len(g.Aliens)}
+	for _, alien := range g.Aliens {
+		if !alien.Alive {
+			run.AliensDead++
+		}
+	}
+	return run, cities, nil
+}
+
+// WriteJSON writes the result as indented JSON
+func (r *BatchResult) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the result as a long table of metric, key and value, like `survival,Akel,0.42`,
+// `destroyed_cities,3,17` (17 games ended with 3 cities destroyed) or `ticks,mean,12.5`. Games are not written.
+func (r *BatchResult) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{{"metric", "key", "value"}, {"runs", "", strconv.Itoa(len(r.Runs))}}
+	for _, name := range r.cities {
+		records = append(records, []string{"survival", name, formatFloat(r.Survival[name])})
+	}
+	var destroyed []int
+	for count := range r.DestroyedCities {
+		destroyed = append(destroyed, count)
+	}
+	sort.Ints(destroyed)
+	for _, count := range destroyed {
+		records = append(records, []string{"destroyed_cities", strconv.Itoa(count), strconv.Itoa(r.DestroyedCities[count])})
+	}
+	for _, metric := range []struct {
+		name         string
+		distribution Distribution
+	}{{"ticks", r.Ticks}, {"casualty_rate", r.CasualtyRate}} {
+		d := metric.distribution
+		records = append(records,
+			[]string{metric.name, "min", formatFloat(d.Min)},
+			[]string{metric.name, "max", formatFloat(d.Max)},
+			[]string{metric.name, "mean", formatFloat(d.Mean)},
+			[]string{metric.name, "p50", formatFloat(d.P50)},
+			[]string{metric.name, "p90", formatFloat(d.P90)},
+		)
+	}
+	return writer.WriteAll(records)
+}
+
+// formatFloat prints a value of a CSV table, as short as possible
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,104 @@
+package alien_invastion
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNewDistribution(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Distribution
+	}{
+		{name: "Empty", want: Distribution{}},
+		{name: "One value", values: []float64{3}, want: Distribution{Min: 3, Max: 3, Mean: 3, P50: 3, P90: 3}},
+		{
+			name:   "Ten values",
+			values: []float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5},
+			want:   Distribution{Min: 1, Max: 10, Mean: 5.5, P50: 5, P90: 9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newDistribution(tt.values))
+		})
+	}
+}
+
+// batchScenario is the classic game on the sample map, with a fixed seed
+func batchScenario(aliens int) *Scenario {
+	s := NewScenario()
+	s.Map = "test_resources/sample_map.txt"
+	s.Aliens.Count = aliens
+	seed := int64(100)
+	s.Seed = &seed
+	return s
+}
+
+func TestScenario_RunBatch(t *testing.T) {
+	s := batchScenario(4)
+	result, err := s.RunBatch(50, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, len(result.Runs))
+
+	// Parallel games end the same way
+	parallel, err := s.RunBatch(50, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, result, parallel)
+
+	// Every game is the scenario played with its own seed
+	for i, run := range result.Runs {
+		assert.Equal(t, int64(100+i), run.Seed)
+		single, _, err := s.play(run.Seed)
+		assert.NoError(t, err)
+		assert.Equal(t, single, run)
+		assert.Equal(t, 4, run.Aliens)
+	}
+
+	games := 0
+	for count, runs := range result.DestroyedCities {
+		assert.GreaterOrEqual(t, count, 0)
+		games += runs
+	}
+	assert.Equal(t, 50, games)
+	assert.Equal(t, 9, len(result.Survival))
+	for _, share := range result.Survival {
+		assert.GreaterOrEqual(t, share, 0.0)
+		assert.LessOrEqual(t, share, 1.0)
+	}
+	assert.LessOrEqual(t, result.CasualtyRate.Max, 1.0)
+	assert.GreaterOrEqual(t, result.Ticks.Min, 1.0)
+}
+
+func TestScenario_RunBatchErrors(t *testing.T) {
+	_, err := batchScenario(4).RunBatch(0, 1)
+	assert.Error(t, err)
+	_, err = batchScenario(100).RunBatch(3, 2)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "seed 100")
+	}
+}
+
+func TestBatchResult_Write(t *testing.T) {
+	result, _ := batchScenario(2).RunBatch(10, 2)
+
+	var csv bytes.Buffer
+	assert.NoError(t, result.WriteCSV(&csv))
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	assert.Equal(t, []string{"metric,key,value", "runs,,10"}, lines[:2])
+	assert.True(t, strings.HasPrefix(lines[2], "survival,Akel,"))
+	assert.Contains(t, lines, "ticks,mean,"+formatFloat(result.Ticks.Mean))
+	assert.Equal(t, "casualty_rate,p90,"+formatFloat(result.CasualtyRate.P90), lines[len(lines)-1])
+
+	var out bytes.Buffer
+	assert.NoError(t, result.WriteJSON(&out))
+	var decoded BatchResult
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, result.Runs, decoded.Runs)
+	assert.Equal(t, result.Survival, decoded.Survival)
+	assert.Equal(t, result.DestroyedCities, decoded.DestroyedCities)
+}
@@ -0,0 +1,77 @@
+package alien_invastion
+
+// SetCityCapacity sets how many aliens a city can hold, unless the city has its own capacity. 1 by default, 0 means unlimited.
+func (m *GameMap) SetCityCapacity(capacity int) {
+	m.cityCapacity = capacity
+}
+
+// SetGatherThreshold destroys a city once given number of aliens gather in it, 0 to disable.
+// Aliens gather by entering a city peacefully, as friends of aliens in it, or by being placed in it, with or without faction.
+// Aliens gathered are killed together with the city.
+func (m *GameMap) SetGatherThreshold(threshold int) {
+	m.gatherThreshold = threshold
+}
+
+// SetStackedPlacement lets AssignAliens put more than one alien into a city, up to its capacity.
+// Aliens placed together don't fight until one of them moves on, or another alien enters.
+func (m *GameMap) SetStackedPlacement(stacked bool) {
+	m.stackedPlacement = stacked
+}
+
+// capacity returns max aliens in the city, 0 means unlimited. Cities wired by hand hold 1 alien unless specified.
+func (c *City) capacity() int {
+	switch {
+	case c.Capacity > 0:
+		return c.Capacity
+	case c.gameMap != nil:
+		return c.gameMap.cityCapacity
+	default:
+		return 1
+	}
+}
+
+// IsFull tells if no more alien can enter the city peacefully
+func (c *City) IsFull() bool {
+	capacity := c.capacity()
+	return capacity > 0 && len(c.Aliens) >= capacity
+}
+
+// join puts the alien into the city, and claims the city for its faction
+func (c *City) join(alien *Alien) {
+	c.admit(alien)
+	c.claim(alien)
+}
+
+// admit puts the alien into the city, without claiming it
+func (c *City) admit(alien *Alien) {
+	c.Aliens = append(c.Aliens, alien)
+	if c.gameMap != nil {
+		c.gameMap.enroll(c)
+	}
+}
+
+// leave removes the alien from the city, false if the alien is not in it
+func (c *City) leave(alien *Alien) bool {
+	for i, other := range c.Aliens {
+		if other == alien {
+			c.Aliens = append(c.Aliens[:i:i], c.Aliens[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// gather destroys the city and kills aliens in it, once the gather threshold is reached. Defenders never destroy cities.
+func (c *City) gather() {
+	if c.gameMap == nil || !c.Exists || c.gameMap.gatherThreshold <= 0 || len(c.Aliens) < c.gameMap.gatherThreshold || c.Aliens[0].Side == Defenders {
+		return
+	}
+	var numbers []int
+	for _, alien := range c.Aliens {
+		alien.Alive = false
+		numbers = append(numbers, alien.Number)
+	}
+	c.Aliens = nil
+	c.destroy()
+	c.emit(Event{Type: CityDestroyed, City: c.Name, Aliens: numbers})
+}
@@ -0,0 +1,176 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCity_IsFull(t *testing.T) {
+	tests := []struct {
+		name         string
+		mapCapacity  int
+		cityCapacity int
+		aliens       int
+		want         bool
+	}{
+		{
+			name:        "Default capacity",
+			mapCapacity: 1,
+			aliens:      1,
+			want:        true,
+		},
+		{
+			name:        "Empty city",
+			mapCapacity: 1,
+			aliens:      0,
+			want:        false,
+		},
+		{
+			name:         "City capacity overrides map capacity",
+			mapCapacity:  1,
+			cityCapacity: 3,
+			aliens:       2,
+			want:         false,
+		},
+		{
+			name:        "Unlimited",
+			mapCapacity: 0,
+			aliens:      100,
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGameMap()
+			m.SetCityCapacity(tt.mapCapacity)
+			c := m.UpsertCity("Foo")
+			c.Capacity = tt.cityCapacity
+			for i := 0; i < tt.aliens; i++ {
+				c.join(m.AlienIDs().NewAlien())
+			}
+			assert.Equal(t, tt.want, c.IsFull())
+		})
+	}
+}
+
+func TestCity_gather(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar")
+	m.SetCityCapacity(0)
+	m.SetGatherThreshold(3)
+	bar := m.cities["Bar"]
+	var aliens []*Alien
+	for i := 0; i < 3; i++ {
+		alien := &Alien{Number: i, Alive: true, Strength: 1, Health: 1, Faction: "red"}
+		aliens = append(aliens, alien)
+		m.cities["Foo"].join(alien)
+	}
+
+	m.cities["Foo"].AlienMigrate(aliens[0], bar)
+	m.cities["Foo"].AlienMigrate(aliens[1], bar)
+	assert.True(t, bar.Exists)
+	assert.Equal(t, aliens[:2], bar.Aliens)
+
+	m.cities["Foo"].AlienMigrate(aliens[2], bar)
+	assert.False(t, bar.Exists)
+	assert.Empty(t, bar.Aliens)
+	for _, alien := range aliens {
+		assert.False(t, alien.Alive)
+	}
+	assert.Equal(t, []Event{{Type: CityDestroyed, City: "Bar", Aliens: []int{0, 1, 2}}}, m.Events())
+}
+
+func TestGameMap_gatherOnPlacement(t *testing.T) {
+	destroyed := func(name string, aliens ...int) Event {
+		return Event{Type: CityDestroyed, City: name, Aliens: aliens}
+	}
+	tests := []struct {
+		name       string
+		cities     string
+		aliens     int
+		place      func(m *GameMap, aliens []*Alien) error
+		wantErr    bool
+		wantExists bool
+		wantEvents []Event
+	}{
+		{
+			name:   "Stacked placement",
+			cities: "Foo",
+			aliens: 2,
+			place: func(m *GameMap, aliens []*Alien) error {
+				m.SetStackedPlacement(true)
+				return m.AssignAliens(aliens)
+			},
+			wantEvents: []Event{destroyed("Foo", 0, 1)},
+		},
+		{
+			name:   "Stacked placement never lands in ruins",
+			cities: "Foo\nBar",
+			aliens: 6,
+			place: func(m *GameMap, aliens []*Alien) error {
+				m.SetStackedPlacement(true)
+				return m.AssignAliens(aliens)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Placed at the city",
+			cities: "Foo",
+			aliens: 2,
+			place: func(m *GameMap, aliens []*Alien) error {
+				if err := m.PlaceAlien(aliens[0], "Foo"); err != nil {
+					return err
+				}
+				return m.PlaceAlien(aliens[1], "Foo")
+			},
+			wantEvents: []Event{destroyed("Foo", 0, 1)},
+		},
+		{
+			name:   "Below threshold",
+			cities: "Foo",
+			aliens: 1,
+			place: func(m *GameMap, aliens []*Alien) error {
+				return m.PlaceAlien(aliens[0], "Foo")
+			},
+			wantExists: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString(tt.cities)
+			m.SetCityCapacity(0)
+			m.SetGatherThreshold(2)
+			// Aliens without faction gather as well
+			aliens := make([]*Alien, tt.aliens)
+			for i := range aliens {
+				aliens[i] = &Alien{Number: i, Alive: true}
+			}
+			assert.Equal(t, tt.wantErr, tt.place(m, aliens) != nil)
+			for _, city := range m.Cities() {
+				assert.Equal(t, tt.wantExists, city.Exists)
+				if !city.Exists {
+					// No alien is left alive in a ruin
+					assert.Empty(t, city.Aliens)
+				}
+			}
+			if tt.wantEvents != nil {
+				assert.Equal(t, tt.wantEvents, m.Events())
+			} else if !tt.wantErr {
+				assert.Empty(t, m.Events())
+			}
+		})
+	}
+}
+
+func TestGameMap_UpdateWithCrowd(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Bar capacity=2\nFoo west=Bar")
+	red := &Alien{Number: 0, Alive: true, Strength: 1, Health: 1, Faction: "red"}
+	friend := &Alien{Number: 1, Alive: true, Strength: 1, Health: 1, Faction: "red"}
+	m.cities["Foo"].Aliens = []*Alien{red, friend}
+
+	// Both walk to Bar without fighting
+	assert.True(t, m.Update())
+	assert.Equal(t, []*Alien{red, friend}, m.cities["Bar"].Aliens)
+	assert.Empty(t, m.cities["Foo"].Aliens)
+	assert.Empty(t, m.Events())
+
+}
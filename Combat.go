@@ -0,0 +1,149 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+type CombatOutcome int
+
+const (
+	MutualDestruction CombatOutcome = iota
+	AttackerWins
+	DefenderWins
+	AttackerRetreats
+)
+
+func (o CombatOutcome) String() string {
+	switch o {
+	case MutualDestruction:
+		return "mutual-destruction"
+	case AttackerWins:
+		return "attacker-wins"
+	case DefenderWins:
+		return "defender-wins"
+	case AttackerRetreats:
+		return "attacker-retreats"
+	default:
+		return "unknown"
+	}
+}
+
+// CombatResolver decides how a fight between the alien entering a city (attacker) and the alien in it (defender) ends.
+// Resolvers may wound the aliens, but killing the loser and damaging the city is done by the city.
+type CombatResolver interface {
+	Resolve(rng *rand.Rand, attacker, defender *Alien) CombatOutcome
+}
+
+// MutualDestructionResolver is the classic rule : both aliens die, always
+type MutualDestructionResolver struct {
+}
+
+func (r MutualDestructionResolver) Resolve(rng *rand.Rand, attacker, defender *Alien) CombatOutcome {
+	return MutualDestruction
+}
+
+// StrengthResolver lets the stronger alien more likely to win. The attacker may retreat before fighting with RetreatChance.
+// The winner is wounded by up to loser's strength, and dies as well if its health drops to 0. Negative strength counts as 0.
+type StrengthResolver struct {
+	RetreatChance float64
+}
+
+func (r StrengthResolver) Resolve(rng *rand.Rand, attacker, defender *Alien) CombatOutcome {
+	if rng.Float64() < r.RetreatChance {
+		return AttackerRetreats
+	}
+
+	outcome, winner, loser := DefenderWins, defender, attacker
+	total := attacker.Strength + defender.Strength
+	if total <= 0 || rng.Intn(total) < attacker.Strength {
+		outcome, winner, loser = AttackerWins, attacker, defender
+	}
+	wound := loser.Strength
+	if wound < 0 {
+		wound = 0
+	}
+	winner.Health -= rng.Intn(wound + 1)
+	if winner.Health <= 0 {
+		return MutualDestruction
+	}
+	return outcome
+}
+
+// damage is the damage the alien deals to the city it fights in, its strength but at least 1
+func (a *Alien) damage() int {
+	if a.Strength < 1 {
+		return 1
+	}
+	return a.Strength
+}
+
+// CombatResolverFromString returns one of the built-in resolvers by name : mutual or strength
+func CombatResolverFromString(name string, retreatChance float64) (CombatResolver, error) {
+	switch name {
+	case "mutual":
+		return MutualDestructionResolver{}, nil
+	case "strength":
+		return StrengthResolver{RetreatChance: retreatChance}, nil
+	default:
+		return nil, fmt.Errorf("unknown combat resolver %s", name)
+	}
+}
+
+// SetCombatResolver changes how fights end, MutualDestructionResolver by default
+func (m *GameMap) SetCombatResolver(resolver CombatResolver) {
+	m.combatResolver = resolver
+}
+
+func (c *City) combatResolver() CombatResolver {
+	if c.gameMap == nil || c.gameMap.combatResolver == nil {
+		return MutualDestructionResolver{}
+	}
+	return c.gameMap.combatResolver
+}
+
+// fight resolves a fight between the alien entering the city and a rival in it, and damages the city by strength of both aliens, reduced by city's defense.
+// An alien deals at least 1 damage, so a city without hit points or defense declared is destroyed by the first fight, whatever the strength of aliens.
+// A fortified city may survive several fights before being destroyed. entered is false if the attacker retreats without fighting.
+func (c *City) fight(attacker, defender *Alien) (entered bool) {
+	outcome := c.combatResolver().Resolve(c.random(), attacker, defender)
+	switch outcome {
+	case AttackerRetreats:
+		c.emit(Event{Type: AlienRetreated, City: c.Name, Aliens: []int{attacker.Number, defender.Number}})
+		return false
+	case AttackerWins:
+		defender.Alive = false
+		c.leave(defender)
+		c.join(attacker)
+		c.emit(Event{Type: AlienKilled, City: c.Name, Aliens: []int{defender.Number, attacker.Number}})
+	case DefenderWins:
+		attacker.Alive = false
+		c.emit(Event{Type: AlienKilled, City: c.Name, Aliens: []int{attacker.Number, defender.Number}})
+	default:
+		attacker.Alive = false
+		defender.Alive = false
+		c.leave(defender)
+		if attacker.Side != defender.Side {
+			c.emit(Event{Type: AlienKilled, City: c.Name, Aliens: []int{attacker.Number, defender.Number}})
+			c.emit(Event{Type: AlienKilled, City: c.Name, Aliens: []int{defender.Number, attacker.Number}})
+		}
+	}
+
+	// Defenders protect the city, it is never damaged by their fights
+	if attacker.Side != defender.Side {
+		return true
+	}
+	damage := attacker.damage() + defender.damage() - c.Defense
+	if damage < 0 {
+		damage = 0
+	}
+	c.HitPoints -= damage
+	aliens := []int{attacker.Number, defender.Number}
+	if c.HitPoints > 0 {
+		c.emit(Event{Type: CityDamaged, City: c.Name, Aliens: aliens, HitPoints: c.HitPoints})
+		return true
+	}
+	c.destroy()
+	c.emit(Event{Type: CityDestroyed, City: c.Name, Aliens: aliens})
+	return true
+}
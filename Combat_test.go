@@ -0,0 +1,236 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+// fixedResolver always ends fights in the same way
+type fixedResolver CombatOutcome
+
+func (r fixedResolver) Resolve(rng *rand.Rand, attacker, defender *Alien) CombatOutcome {
+	return CombatOutcome(r)
+}
+
+func TestCity_fightOutcomes(t *testing.T) {
+	tests := []struct {
+		name          string
+		outcome       CombatOutcome
+		wantEntered   bool
+		wantAttacker  bool
+		wantDefender  bool
+		wantOccupant  int
+		wantHitPoints int
+		wantEvents    []EventType
+	}{
+		{
+			name:          "Mutual destruction",
+			outcome:       MutualDestruction,
+			wantEntered:   true,
+			wantOccupant:  -1,
+			wantHitPoints: 3,
+			wantEvents:    []EventType{CityDamaged},
+		},
+		{
+			name:          "Attacker wins",
+			outcome:       AttackerWins,
+			wantEntered:   true,
+			wantAttacker:  true,
+			wantOccupant:  1,
+			wantHitPoints: 3,
+			wantEvents:    []EventType{AlienKilled, CityDamaged},
+		},
+		{
+			name:          "Defender wins",
+			outcome:       DefenderWins,
+			wantEntered:   true,
+			wantDefender:  true,
+			wantOccupant:  2,
+			wantHitPoints: 3,
+			wantEvents:    []EventType{AlienKilled, CityDamaged},
+		},
+		{
+			name:          "Attacker retreats",
+			outcome:       AttackerRetreats,
+			wantEntered:   false,
+			wantAttacker:  true,
+			wantDefender:  true,
+			wantOccupant:  2,
+			wantHitPoints: 5,
+			wantEvents:    []EventType{AlienRetreated},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGameMap()
+			m.SetCombatResolver(fixedResolver(tt.outcome))
+			c := m.UpsertCity("city1")
+			c.HitPoints = 5
+			attacker := &Alien{Number: 1, Alive: true, Strength: 1, Health: 1}
+			defender := &Alien{Number: 2, Alive: true, Strength: 1, Health: 1}
+			c.Aliens = []*Alien{defender}
+			assert.Equal(t, tt.wantEntered, c.alienArrive(attacker))
+			assert.Equal(t, tt.wantAttacker, attacker.Alive)
+			assert.Equal(t, tt.wantDefender, defender.Alive)
+			if tt.wantOccupant < 0 {
+				assert.Empty(t, c.Aliens)
+			} else {
+				assert.Len(t, c.Aliens, 1)
+				assert.Equal(t, tt.wantOccupant, c.Aliens[0].Number)
+			}
+			assert.Equal(t, tt.wantHitPoints, c.HitPoints)
+			var events []EventType
+			for _, e := range m.Events() {
+				events = append(events, e.Type)
+			}
+			assert.Equal(t, tt.wantEvents, events)
+		})
+	}
+}
+
+func TestCity_fightDamage(t *testing.T) {
+	tests := []struct {
+		name          string
+		strength      int
+		hitPoints     int
+		defense       int
+		wantHitPoints int
+	}{
+		{name: "Zero strength deals 1 damage", strength: 0, hitPoints: 1, wantHitPoints: -1},
+		{name: "Negative strength deals 1 damage", strength: -3, hitPoints: 5, wantHitPoints: 3},
+		{name: "Strength", strength: 2, hitPoints: 5, wantHitPoints: 1},
+		{name: "Defense", strength: 2, hitPoints: 5, defense: 3, wantHitPoints: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGameMap()
+			c := m.UpsertCity("city1")
+			c.HitPoints, c.Defense = tt.hitPoints, tt.defense
+			c.Aliens = []*Alien{{Number: 2, Alive: true, Strength: tt.strength}}
+			c.alienArrive(&Alien{Number: 1, Alive: true, Strength: tt.strength})
+			assert.Equal(t, tt.wantHitPoints, c.HitPoints)
+			assert.Equal(t, tt.wantHitPoints <= 0, !c.Exists)
+		})
+	}
+}
+
+func TestCity_AlienMigrateRetreat(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar")
+	m.SetCombatResolver(fixedResolver(AttackerRetreats))
+	attacker, defender := m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien()
+	m.cities["Foo"].Aliens = []*Alien{attacker}
+	m.cities["Bar"].Aliens = []*Alien{defender}
+	m.cities["Foo"].AlienMigrate(attacker, m.cities["Bar"])
+	assert.Equal(t, []*Alien{attacker}, m.cities["Foo"].Aliens)
+	assert.Equal(t, []*Alien{defender}, m.cities["Bar"].Aliens)
+}
+
+func TestMutualDestructionResolver_Resolve(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	ids := &AlienIDs{}
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, MutualDestruction, MutualDestructionResolver{}.Resolve(rng, ids.NewAlien(), ids.NewAlien()))
+	}
+}
+
+func TestStrengthResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name            string
+		resolver        StrengthResolver
+		attacker        *Alien
+		defender        *Alien
+		wantOutcomes    []CombatOutcome
+		notWantOutcomes []CombatOutcome
+	}{
+		{
+			name:         "Always retreat",
+			resolver:     StrengthResolver{RetreatChance: 1},
+			attacker:     &Alien{Strength: 1, Health: 1},
+			defender:     &Alien{Strength: 1, Health: 1},
+			wantOutcomes: []CombatOutcome{AttackerRetreats},
+		},
+		{
+			name:            "Much stronger attacker never loses",
+			resolver:        StrengthResolver{},
+			attacker:        &Alien{Strength: 1000, Health: 1000000},
+			defender:        &Alien{Strength: 0, Health: 1},
+			wantOutcomes:    []CombatOutcome{AttackerWins},
+			notWantOutcomes: []CombatOutcome{DefenderWins, AttackerRetreats, MutualDestruction},
+		},
+		{
+			name:            "Even fight has both winners",
+			resolver:        StrengthResolver{},
+			attacker:        &Alien{Strength: 1, Health: 1000000},
+			defender:        &Alien{Strength: 1, Health: 1000000},
+			wantOutcomes:    []CombatOutcome{AttackerWins, DefenderWins},
+			notWantOutcomes: []CombatOutcome{AttackerRetreats},
+		},
+		{
+			name:            "Negative strength",
+			resolver:        StrengthResolver{},
+			attacker:        &Alien{Strength: -1, Health: 1},
+			defender:        &Alien{Strength: -1, Health: 1},
+			wantOutcomes:    []CombatOutcome{AttackerWins},
+			notWantOutcomes: []CombatOutcome{AttackerRetreats, MutualDestruction},
+		},
+		{
+			name:            "Fragile winner may die as well",
+			resolver:        StrengthResolver{},
+			attacker:        &Alien{Strength: 5, Health: 1},
+			defender:        &Alien{Strength: 5, Health: 1},
+			wantOutcomes:    []CombatOutcome{MutualDestruction},
+			notWantOutcomes: []CombatOutcome{AttackerRetreats},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			got := map[CombatOutcome]bool{}
+			for i := 0; i < 200; i++ {
+				attacker, defender := *tt.attacker, *tt.defender
+				got[tt.resolver.Resolve(rng, &attacker, &defender)] = true
+			}
+			for _, outcome := range tt.wantOutcomes {
+				assert.Truef(t, got[outcome], "want %v", outcome)
+			}
+			for _, outcome := range tt.notWantOutcomes {
+				assert.Falsef(t, got[outcome], "not want %v", outcome)
+			}
+		})
+	}
+}
+
+func TestCombatResolverFromString(t *testing.T) {
+	resolver, err := CombatResolverFromString("mutual", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, MutualDestructionResolver{}, resolver)
+	resolver, err = CombatResolverFromString("strength", 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, StrengthResolver{RetreatChance: 0.5}, resolver)
+	_, err = CombatResolverFromString("rock-paper-scissors", 0)
+	assert.Error(t, err)
+}
+
+func TestGameMap_SetSeed(t *testing.T) {
+	run := func(seed int64) (string, []Event) {
+		m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+		m.SetSeed(seed)
+		m.SetCombatResolver(StrengthResolver{RetreatChance: 0.2})
+		var aliens []*Alien
+		for i := 0; i < 6; i++ {
+			aliens = append(aliens, &Alien{Number: i, Alive: true, Strength: 2, Health: 3})
+		}
+		_ = m.AssignAliens(aliens)
+		for m.Update() {
+		}
+		return m.DumpMap(), m.Events()
+	}
+	dumped1, events1 := run(42)
+	dumped2, events2 := run(42)
+	assert.Equal(t, dumped1, dumped2)
+	assert.Equal(t, len(events1), len(events2))
+	for i := range events1 {
+		assert.Equal(t, events1[i].String(), events2[i].String())
+	}
+}
@@ -0,0 +1,279 @@
+package alien_invastion
+
+// compactMap keeps cities and roads of a large map in flat arrays indexed by city and road IDs, instead of objects.
+// City IDs are positions of cities in the map, road IDs are positions of roads in order of creation.
+// City and Road objects are only created once the game or a caller touches them, see GameMap.city and GameMap.road.
+type compactMap struct {
+	names      []string
+	ids        map[string]int32 // Built on first lookup by name
+	offsets    []int32          // Roads of city i are incidence[offsets[i]:offsets[i+1]], in order of creation
+	incidence  []int32
+	from       []int32
+	to         []int32
+	directions []uint8 // Invalid on graph maps
+	oneWay     bitset
+	destroyed  bitset // Roads destroyed before the map was compacted
+	ruins      bitset // Cities destroyed before the map was compacted
+	lengths    map[int32]int32
+	roadNames  map[int32]string
+	roads      map[int32]*Road // Roads created so far
+}
+
+// bitset is a set of IDs, one bit each
+type bitset []uint64
+
+func newBitset(size int) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+func newCompactMap(names []string, roads int) *compactMap {
+	return &compactMap{
+		names:      names,
+		offsets:    make([]int32, len(names)+1),
+		from:       make([]int32, 0, roads),
+		to:         make([]int32, 0, roads),
+		directions: make([]uint8, 0, roads),
+		oneWay:     newBitset(roads),
+		destroyed:  newBitset(roads),
+		ruins:      newBitset(len(names)),
+		lengths:    make(map[int32]int32),
+		roadNames:  make(map[int32]string),
+		roads:      make(map[int32]*Road),
+	}
+}
+
+// addRoad adds a road after the ones added before, and returns its ID
+func (c *compactMap) addRoad(from, to int32, direction Direction) int32 {
+	id := int32(len(c.from))
+	c.from = append(c.from, from)
+	c.to = append(c.to, to)
+	c.directions = append(c.directions, uint8(direction))
+	return id
+}
+
+// link builds roads of every city once all roads are added. Roads of a city keep their order of creation,
+// like roads connected one by one, so a compact map plays exactly like the map it comes from.
+func (c *compactMap) link() {
+	for i := range c.from {
+		c.offsets[c.from[i]+1]++
+		c.offsets[c.to[i]+1]++
+	}
+	for i := 1; i < len(c.offsets); i++ {
+		c.offsets[i] += c.offsets[i-1]
+	}
+	next := make([]int32, len(c.names))
+	copy(next, c.offsets)
+	c.incidence = make([]int32, c.offsets[len(c.names)])
+	for i := range c.from {
+		for _, city := range [2]int32{c.from[i], c.to[i]} {
+			c.incidence[next[city]] = int32(i)
+			next[city]++
+		}
+	}
+}
+
+// id finds the city by name
+func (c *compactMap) id(name string) (int, bool) {
+	if c.ids == nil {
+		c.ids = make(map[string]int32, len(c.names))
+		for i, name := range c.names {
+			c.ids[name] = int32(i)
+		}
+	}
+	id, found := c.ids[name]
+	return int(id), found
+}
+
+// Compact copies cities and roads of the map into a compact map, which takes a fraction of the memory for large maps.
+// Compact maps have the same API and play exactly like the map they come from. City objects are only created
+// once a city is touched, by units landing or moving, or by the API, so sparse invasions of huge maps stay cheap.
+// Cities(), DumpMap() and placement strategies other than uniform go through all cities, so they are slower.
+// Units and rules are not copied. The map compacted is kept until collected, so building a map then compacting it
+// takes the memory of both at its peak, only NewCompactGridGameMap builds a compact map without a regular one.
+func (m *GameMap) Compact() *GameMap {
+	names := make([]string, len(m.cityList))
+	for i := range m.cityList {
+		names[i] = m.city(i).Name
+	}
+	roads := m.roadCount()
+	compact := newCompactMap(names, roads)
+	for i := 0; i < roads; i++ {
+		road := m.roadAt(i)
+		id := compact.addRoad(int32(road.From.index), int32(road.To.index), road.Direction)
+		if road.OneWay {
+			compact.oneWay.set(int(id))
+		}
+		if road.Destroyed {
+			compact.destroyed.set(int(id))
+		}
+		if road.Length > 0 {
+			compact.lengths[id] = int32(road.Length)
+		}
+		if road.Name != "" {
+			compact.roadNames[id] = road.Name
+		}
+	}
+	compact.link()
+
+	ret := NewGameMapWithDirections(m.directions)
+	ret.graph = m.graph
+	ret.compact = compact
+	ret.cityList = make([]*City, len(names))
+	for i, city := range m.cityList {
+		if !city.Exists {
+			compact.ruins.set(i)
+		}
+		// Few cities have attributes, they are created right away to keep them
+		if city.declaration() != city.Name {
+			copied := ret.city(i)
+			copied.HitPoints, copied.Defense, copied.Capacity, copied.Garrison = city.HitPoints, city.Defense, city.Capacity, city.Garrison
+			copied.hitPoints = city.hitPoints
+		}
+	}
+	return ret
+}
+
+// IsCompact tells if the map is a compact map, see Compact
+func (m *GameMap) IsCompact() bool {
+	return m.compact != nil
+}
+
+// city returns the city by its ID, and creates it first if it has not been touched in a compact map
+func (m *GameMap) city(id int) *City {
+	if city := m.cityList[id]; city != nil {
+		return city
+	}
+	city := newCity(m.compact.names[id])
+	city.gameMap = m
+	city.index = id
+	city.Exists = !m.compact.ruins.has(id)
+	m.cities[city.Name] = city
+	m.cityList[id] = city
+	return city
+}
+
+// cityExists tells if the city is not destroyed, without creating it
+func (m *GameMap) cityExists(id int) bool {
+	if city := m.cityList[id]; city != nil {
+		return city.Exists
+	}
+	return !m.compact.ruins.has(id)
+}
+
+// lookup finds the city by name, nil if no such city
+func (m *GameMap) lookup(name string) *City {
+	if city, found := m.cities[name]; found || m.compact == nil {
+		return city
+	}
+	if id, found := m.compact.id(name); found {
+		return m.city(id)
+	}
+	return nil
+}
+
+// road returns the road of a compact map by its ID, and creates it first if it has not been touched
+func (m *GameMap) road(id int32) *Road {
+	if road, found := m.compact.roads[id]; found {
+		return road
+	}
+	road := &Road{
+		Name:      m.compact.roadNames[id],
+		Direction: Direction(m.compact.directions[id]),
+		From:      m.city(int(m.compact.from[id])),
+		To:        m.city(int(m.compact.to[id])),
+		OneWay:    m.compact.oneWay.has(int(id)),
+		Length:    int(m.compact.lengths[id]),
+		Destroyed: m.compact.destroyed.has(int(id)),
+	}
+	m.compact.roads[id] = road
+	return road
+}
+
+// roadCount counts roads of the map, destroyed ones included
+func (m *GameMap) roadCount() int {
+	if m.compact == nil {
+		return len(m.roads)
+	}
+	return len(m.compact.from) + len(m.roads)
+}
+
+// roadAt returns the road by its position in order of creation
+func (m *GameMap) roadAt(i int) *Road {
+	if m.compact == nil {
+		return m.roads[i]
+	}
+	if i < len(m.compact.from) {
+		return m.road(int32(i))
+	}
+	return m.roads[i-len(m.compact.from)]
+}
+
+// roadDestroyed tells if the road at given position is destroyed, without creating it
+func (m *GameMap) roadDestroyed(i int) bool {
+	if m.compact != nil && i < len(m.compact.from) {
+		if _, found := m.compact.roads[int32(i)]; !found {
+			return m.compact.destroyed.has(i)
+		}
+	}
+	return m.roadAt(i).Destroyed
+}
+
+// wire creates roads of a city of a compact map, and its neighborhoods, the first time they are needed.
+// Cities on the other side of the roads are created as well, but not wired until they are needed.
+func (c *City) wire() {
+	if c.wired || c.gameMap == nil || c.gameMap.compact == nil {
+		return
+	}
+	c.wired = true
+	m := c.gameMap
+	if c.index >= len(m.compact.names) {
+		// Cities added after compaction have their roads already
+		return
+	}
+	for _, id := range m.compact.incidence[m.compact.offsets[c.index]:m.compact.offsets[c.index+1]] {
+		road := m.road(id)
+		c.Roads = append(c.Roads, road)
+		switch {
+		case road.Direction == Invalid:
+		case road.From == c:
+			c.Neighborhoods[road.Direction] = road.To
+		case !road.OneWay:
+			c.Neighborhoods[road.Direction.GetOpposite()] = road.From
+		}
+	}
+}
+
+// sketch makes a throwaway copy of a city not touched yet, with its roads, to print it without creating it
+func (m *GameMap) sketch(id int) *City {
+	city := &City{Name: m.compact.names[id], Exists: m.cityExists(id), HitPoints: 1}
+	for _, road := range m.compact.incidence[m.compact.offsets[id]:m.compact.offsets[id+1]] {
+		from, to := int(m.compact.from[road]), int(m.compact.to[road])
+		other := from
+		if from == id {
+			other = to
+		}
+		neighbor := &City{Name: m.compact.names[other], Exists: m.cityExists(other)}
+		copied := &Road{
+			Name:      m.compact.roadNames[road],
+			Direction: Direction(m.compact.directions[road]),
+			From:      neighbor,
+			To:        city,
+			OneWay:    m.compact.oneWay.has(int(road)),
+			Length:    int(m.compact.lengths[road]),
+			Destroyed: m.compact.destroyed.has(int(road)),
+		}
+		if from == id {
+			copied.From, copied.To = city, neighbor
+		}
+		city.Roads = append(city.Roads, copied)
+	}
+	return city
+}
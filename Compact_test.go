@@ -0,0 +1,182 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBitset(t *testing.T) {
+	b := newBitset(130)
+	assert.Equal(t, 3, len(b))
+	b.set(0)
+	b.set(64)
+	b.set(129)
+	for i := 0; i < 130; i++ {
+		assert.Equal(t, i == 0 || i == 64 || i == 129, b.has(i), i)
+	}
+}
+
+func TestGameMap_Compact(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser StreamParser
+		path   string
+		patch  func(m *GameMap)
+	}{
+		{name: "Compass map", path: "test_resources/sample_map.txt"},
+		{name: "Graph map", parser: StreamParser{Graph: true}, path: "test_resources/graph_map.txt"},
+		{
+			name: "Destroyed cities and roads",
+			path: "test_resources/sample_map.txt",
+			patch: func(m *GameMap) {
+				_ = m.destroyCity("Beth")
+				_ = m.DestroyRoad("Ur-Gorath", "Gresal")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, errs := tt.parser.ParseFile(tt.path)
+			assert.Empty(t, errs)
+			if tt.patch != nil {
+				tt.patch(m)
+			}
+			compact := m.Compact()
+			assert.True(t, compact.IsCompact())
+			assert.Equal(t, m.DumpMap(), compact.DumpMap())
+			// Printing the map doesn't create cities
+			for _, city := range compact.cityList {
+				assert.Nil(t, city)
+			}
+			assert.Equal(t, m.ExistCityCount(), compact.ExistCityCount())
+			assert.Equal(t, m.IsGraph(), compact.IsGraph())
+		})
+	}
+}
+
+func TestStreamParser_Compact(t *testing.T) {
+	m, errs := (&StreamParser{Compact: true}).ParseFile("test_resources/sample_map.txt")
+	assert.Empty(t, errs)
+	assert.True(t, m.IsCompact())
+	assert.NotNil(t, m.GetExistCity("Ur-Gorath"))
+}
+
+func TestGameMap_CompactKeepsAttributes(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar hp=3 defense=1\nBar capacity=2 defenders=1\nBaz west=Bar:3")
+	compact := m.Compact()
+	// Cities with attributes are kept as they are, the others are not created until touched
+	assert.NotNil(t, compact.cityList[0])
+	assert.NotNil(t, compact.cityList[1])
+	assert.Nil(t, compact.cityList[2])
+	assert.Equal(t, m.DumpMap(), compact.DumpMap())
+	assert.Equal(t, 1, len(compact.PlaceDefenders(Guard{})))
+}
+
+func TestGameMap_CompactAPI(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+	compact := m.Compact()
+
+	assert.Nil(t, compact.GetExistCity("Atlantis"))
+	akel := compact.GetExistCity("Akel")
+	if assert.NotNil(t, akel) {
+		assert.Equal(t, "Akel", akel.Name)
+		assert.Equal(t, akel, compact.UpsertCity("Akel"))
+		assert.Equal(t, 1, len(akel.Exits()))
+		assert.Equal(t, "Beth", akel.Neighborhoods[South].Name)
+	}
+
+	// New cities and roads are added like in any map
+	assert.NoError(t, compact.UpdateCityWithNeighborhood("Akel", North, "Atlantis"))
+	assert.Contains(t, compact.DumpMap(), "Akel north=Atlantis south=Beth")
+	assert.Contains(t, compact.DumpMap(), "Atlantis south=Akel")
+
+	assert.NoError(t, compact.DestroyRoad("Akel", "Beth"))
+	assert.Error(t, compact.DestroyRoad("Akel", "Beth"))
+	assert.NoError(t, compact.destroyCity("Delmon"))
+	assert.Error(t, compact.PlaceAlien(compact.AlienIDs().NewAlien(), "Delmon"))
+	assert.NoError(t, compact.PlaceAlien(compact.AlienIDs().NewAlien(), "Rickel"))
+	assert.Equal(t, len(m.Cities())+1, len(compact.Cities()))
+	for _, city := range compact.cityList {
+		assert.NotNil(t, city)
+	}
+}
+
+// play runs a game with most rules on, and returns what happened
+func play(m *GameMap, aliens int) (events []string, dump string, stats Stats) {
+	return playWith(m, aliens, func(m *GameMap) {
+		for m.Update() {
+		}
+	})
+}
+
+// playWith runs a game like play, to the end by run
+func playWith(m *GameMap, aliens int, run func(m *GameMap)) (events []string, dump string, stats Stats) {
+	m.SetSeed(3)
+	m.SetCityCapacity(2)
+	m.SetRoadFailureRate(0.01)
+	m.SetRebuildDelay(5)
+	m.SetSameFactionPolicy(FactionCoexist)
+	m.SetMaxTicks(500)
+	var units []*Alien
+	for i := 0; i < aliens; i++ {
+		alien := &Alien{Number: i, Alive: true, Strength: 1, Health: 1}
+		if i%3 > 0 {
+			alien.Faction = "red"
+		}
+		units = append(units, alien)
+	}
+	_ = m.AssignAliens(units)
+	run(m)
+	for _, e := range m.Events() {
+		events = append(events, e.String())
+	}
+	return events, m.DumpMap(), m.Stats()
+}
+
+func TestGameMap_CompactPlaysTheSame(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func() (regular, compact *GameMap)
+		aliens int
+	}{
+		{
+			name: "Sample map",
+			build: func() (*GameMap, *GameMap) {
+				m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+				other, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+				return m, other.Compact()
+			},
+			aliens: 6,
+		},
+		{
+			name: "Graph map",
+			build: func() (*GameMap, *GameMap) {
+				m, _ := (&StreamParser{Graph: true}).ParseFile("test_resources/graph_map.txt")
+				other, _ := (&StreamParser{Graph: true}).ParseFile("test_resources/graph_map.txt")
+				return m, other.Compact()
+			},
+			aliens: 3,
+		},
+		{
+			name: "Grid map",
+			build: func() (*GameMap, *GameMap) {
+				m, _ := NewGridGameMap(30, 20)
+				compact, _ := NewCompactGridGameMap(30, 20)
+				return m, compact
+			},
+			aliens: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			regular, compact := tt.build()
+			assert.Equal(t, regular.DumpMap(), compact.DumpMap())
+			wantEvents, wantDump, wantStats := play(regular, tt.aliens)
+			events, dump, stats := play(compact, tt.aliens)
+			assert.NotEmpty(t, wantEvents)
+			assert.Equal(t, wantEvents, events)
+			assert.Equal(t, wantDump, dump)
+			assert.Equal(t, wantStats, stats)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package alien_invastion
+
+// Side is which side of the invasion a unit fights for
+type Side int
+
+const (
+	Invaders Side = iota
+	Defenders
+)
+
+func (s Side) String() string {
+	switch s {
+	case Invaders:
+		return "invaders"
+	case Defenders:
+		return "defenders"
+	default:
+		return "unknown"
+	}
+}
+
+// NewDefender creates a human unit defending cities. Defenders never fight each other, and cities are not damaged by their fights.
+// Like NewAlien, it is numbered by the package-wide numbering.
+func NewDefender(strategy MovementStrategy) *Alien {
+	return defaultAlienIDs.NewDefender(strategy)
+}
+
+// PlaceDefenders puts defenders into cities declaring a garrison, like `Foo defenders=2`. Garrisons ignore city capacity.
+func (m *GameMap) PlaceDefenders(strategy MovementStrategy) []*Alien {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var defenders []*Alien
+	for _, city := range m.cityList {
+		if city == nil || !city.Exists {
+			continue
+		}
+		for i := 0; i < city.Garrison; i++ {
+			defender := m.AlienIDs().NewDefender(strategy)
+			city.admit(defender)
+			defenders = append(defenders, defender)
+			m.defended = true
+		}
+	}
+	return defenders
+}
+
+// units counts units still alive of both sides, in cities and on roads
+func (m *GameMap) units() (invaders, defenders int) {
+	count := func(alien *Alien) {
+		switch {
+		case !alien.Alive:
+		case alien.Side == Defenders:
+			defenders++
+		default:
+			invaders++
+		}
+	}
+	for _, city := range m.occupiedCities() {
+		for _, alien := range city.Aliens {
+			count(alien)
+		}
+	}
+	for _, t := range m.transits {
+		count(t.alien)
+	}
+	return
+}
+
+// Winner tells which side wins the invasion : the side still having units when the other side has none.
+// If both sides have units, or neither, defenders win as long as at least half of the cities survive.
+func (m *GameMap) Winner() Side {
+	invaders, defenders := m.units()
+	switch {
+	case invaders == 0 && defenders > 0:
+		return Defenders
+	case defenders == 0 && invaders > 0:
+		return Invaders
+	case m.ExistCityCount()*2 >= len(m.cityList):
+		return Defenders
+	default:
+		return Invaders
+	}
+}
@@ -0,0 +1,173 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAlien_IsRivalWithSides(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     *Alien
+		other *Alien
+		want  bool
+	}{
+		{
+			name:  "Invader and defender",
+			a:     &Alien{Faction: "red"},
+			other: &Alien{Side: Defenders},
+			want:  true,
+		},
+		{
+			name:  "Defenders",
+			a:     &Alien{Side: Defenders},
+			other: &Alien{Side: Defenders},
+			want:  false,
+		},
+		{
+			name:  "Invaders without faction",
+			a:     &Alien{},
+			other: &Alien{},
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.a.IsRival(tt.other))
+		})
+	}
+}
+
+func TestGameMap_PlaceDefenders(t *testing.T) {
+	m, errs := (&StreamParser{}).ParseString("Foo defenders=2 north=Bar\nBar defenders=1\nBaz west=Bar")
+	assert.Empty(t, errs)
+	defenders := m.PlaceDefenders(Guard{})
+	assert.Equal(t, 3, len(defenders))
+	assert.Equal(t, 2, len(m.cities["Foo"].Aliens))
+	assert.Equal(t, 1, len(m.cities["Bar"].Aliens))
+	assert.Empty(t, m.cities["Baz"].Aliens)
+	for _, defender := range defenders {
+		assert.Equal(t, Defenders, defender.Side)
+		assert.Equal(t, Guard{}, defender.Strategy)
+	}
+	assert.Contains(t, m.DumpMap(), "Foo defenders=2 north=Bar")
+}
+
+func TestCity_fightWithDefender(t *testing.T) {
+	tests := []struct {
+		name         string
+		outcome      CombatOutcome
+		wantAttacker bool
+		wantDefender bool
+		wantEvents   []Event
+	}{
+		{
+			name:         "Both die",
+			outcome:      MutualDestruction,
+			wantAttacker: false,
+			wantDefender: false,
+			wantEvents: []Event{
+				{Type: AlienKilled, City: "Foo", Aliens: []int{1, 2}},
+				{Type: AlienKilled, City: "Foo", Aliens: []int{2, 1}},
+			},
+		},
+		{
+			name:         "Defender kills the alien",
+			outcome:      DefenderWins,
+			wantAttacker: false,
+			wantDefender: true,
+			wantEvents:   []Event{{Type: AlienKilled, City: "Foo", Aliens: []int{1, 2}}},
+		},
+		{
+			name:         "Defender repels the alien",
+			outcome:      AttackerRetreats,
+			wantAttacker: true,
+			wantDefender: true,
+			wantEvents:   []Event{{Type: AlienRetreated, City: "Foo", Aliens: []int{1, 2}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGameMap()
+			m.SetCombatResolver(fixedResolver(tt.outcome))
+			c := m.UpsertCity("Foo")
+			attacker := &Alien{Number: 1, Alive: true, Strength: 1, Health: 1}
+			defender := &Alien{Number: 2, Alive: true, Strength: 1, Health: 1, Side: Defenders}
+			c.Aliens = []*Alien{defender}
+			c.alienArrive(attacker)
+			assert.Equal(t, tt.wantAttacker, attacker.Alive)
+			assert.Equal(t, tt.wantDefender, defender.Alive)
+			// City is protected
+			assert.True(t, c.Exists)
+			assert.Equal(t, 1, c.HitPoints)
+			assert.Equal(t, tt.wantEvents, m.Events())
+		})
+	}
+}
+
+func TestGameMap_Winner(t *testing.T) {
+	tests := []struct {
+		name      string
+		invaders  int
+		defenders int
+		destroyed int
+		want      Side
+	}{
+		{
+			name:      "Invaders wiped out",
+			invaders:  0,
+			defenders: 1,
+			destroyed: 3,
+			want:      Defenders,
+		},
+		{
+			name:      "Defenders wiped out",
+			invaders:  1,
+			defenders: 0,
+			want:      Invaders,
+		},
+		{
+			name:      "Half of cities survive",
+			invaders:  1,
+			defenders: 1,
+			destroyed: 2,
+			want:      Defenders,
+		},
+		{
+			name:      "Most cities destroyed",
+			invaders:  1,
+			defenders: 1,
+			destroyed: 3,
+			want:      Invaders,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("A north=B\nB north=C\nC north=D")
+			for i := 0; i < tt.invaders; i++ {
+				m.cities["A"].Aliens = append(m.cities["A"].Aliens, &Alien{Alive: true})
+			}
+			for i := 0; i < tt.defenders; i++ {
+				m.cities["B"].Aliens = append(m.cities["B"].Aliens, &Alien{Alive: true, Side: Defenders})
+			}
+			for _, name := range []string{"D", "C", "A"}[:tt.destroyed] {
+				_ = m.destroyCity(name)
+			}
+			assert.Equal(t, tt.want, m.Winner())
+		})
+	}
+}
+
+func TestGameMap_UpdateWithDefenders(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo defenders=1 north=Bar")
+	m.PlaceDefenders(Guard{})
+	alien := &Alien{Number: 100, Alive: true, Strength: 1, Health: 1}
+	m.cities["Bar"].Aliens = []*Alien{alien}
+
+	// Alien walks into the guarded city and dies with the guard, game is over
+	assert.True(t, m.Update())
+	assert.False(t, alien.Alive)
+	assert.True(t, m.cities["Foo"].Exists)
+	assert.False(t, m.Update())
+	assert.Equal(t, Defenders, m.Winner())
+}
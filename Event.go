@@ -0,0 +1,154 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type EventType int
+
+const (
+	CityDestroyed EventType = iota
+	RoadDestroyed
+	AlienKilled
+	CityDamaged
+	AlienRetreated
+	AliensMerged
+	AlienSpawned
+	CityRebuilt
+	RoadRebuilt
+	AlienExhausted
+	AlienExpired
+)
+
+func (t EventType) String() string {
+	switch t {
+	case CityDestroyed:
+		return "city-destroyed"
+	case RoadDestroyed:
+		return "road-destroyed"
+	case AlienKilled:
+		return "alien-killed"
+	case CityDamaged:
+		return "city-damaged"
+	case AlienRetreated:
+		return "alien-retreated"
+	case AliensMerged:
+		return "aliens-merged"
+	case AlienSpawned:
+		return "alien-spawned"
+	case CityRebuilt:
+		return "city-rebuilt"
+	case RoadRebuilt:
+		return "road-rebuilt"
+	case AlienExhausted:
+		return "alien-exhausted"
+	case AlienExpired:
+		return "alien-expired"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is something happened in the game, emitted by GameMap while updating
+type Event struct {
+	Tick      int
+	Type      EventType
+	City      string   // Name of the city, empty if the event happened on a road
+	From      string   // Name of the city the road starts from, empty if the event happened in a city
+	To        string   // Name of the city the road leads to, empty if the event happened in a city
+	Aliens    []int    // Number of aliens involved
+	Names     []string // Names of aliens involved, empty if they go by their numbers, see AlienIDs.SetNaming
+	HitPoints int      // Hit points left of a damaged city
+}
+
+func (e Event) String() string {
+	switch {
+	case e.Type == CityDestroyed && len(e.Aliens) == 2:
+		return fmt.Sprintf("City %s have been destroyed by alien %v and %v!", e.City, e.alien(0), e.alien(1))
+	case e.Type == CityDestroyed && len(e.Aliens) > 2:
+		return fmt.Sprintf("City %s have been destroyed by aliens %s!", e.City, alienNames(e))
+	case e.Type == CityDestroyed:
+		return fmt.Sprintf("City %s have been destroyed!", e.City)
+	case e.Type == RoadDestroyed && len(e.Aliens) == 2:
+		return fmt.Sprintf("Road between %s and %s have been destroyed by alien %v and %v!", e.From, e.To, e.alien(0), e.alien(1))
+	case e.Type == RoadDestroyed:
+		return fmt.Sprintf("Road between %s and %s have been destroyed!", e.From, e.To)
+	case e.Type == AlienKilled && e.From != "":
+		return fmt.Sprintf("Alien %v was killed on the road between %s and %s!", e.alien(0), e.From, e.To)
+	case e.Type == AlienKilled && len(e.Aliens) == 2:
+		return fmt.Sprintf("Alien %v was killed by alien %v in city %s!", e.alien(0), e.alien(1), e.City)
+	case e.Type == AlienKilled:
+		return fmt.Sprintf("Alien %v was killed in city %s!", e.alien(0), e.City)
+	case e.Type == AlienRetreated:
+		return fmt.Sprintf("Alien %v retreated from city %s guarded by alien %v!", e.alien(0), e.City, e.alien(1))
+	case e.Type == CityDamaged:
+		return fmt.Sprintf("City %s have been damaged by alien %v and %v, %d hit points left!", e.City, e.alien(0), e.alien(1), e.HitPoints)
+	case e.Type == AliensMerged:
+		return fmt.Sprintf("Alien %v merged into alien %v in city %s!", e.alien(0), e.alien(1), e.City)
+	case e.Type == AlienSpawned:
+		return fmt.Sprintf("Alien %v landed in city %s!", e.alien(0), e.City)
+	case e.Type == CityRebuilt:
+		return fmt.Sprintf("City %s have been rebuilt!", e.City)
+	case e.Type == AlienExhausted:
+		return fmt.Sprintf("Alien %v died of exhaustion in city %s!", e.alien(0), e.City)
+	case e.Type == AlienExpired:
+		return fmt.Sprintf("Alien %v died of old age in city %s!", e.alien(0), e.City)
+	case e.Type == RoadRebuilt:
+		return fmt.Sprintf("Road between %s and %s have been rebuilt!", e.From, e.To)
+	default:
+		return fmt.Sprintf("Unknown event %d", e.Type)
+	}
+}
+
+// OnEvent registers a listener called on every event, in the order they happen
+func (m *GameMap) OnEvent(listener func(Event)) {
+	m.listeners = append(m.listeners, listener)
+}
+
+// Events returns all events happened so far
+func (m *GameMap) Events() []Event {
+	return m.events
+}
+
+func (m *GameMap) emit(e Event) {
+	e.Tick = m.tick
+	if m.ids != nil {
+		e.Names = m.ids.names(e.Aliens)
+	}
+	m.events = append(m.events, e)
+	for _, listener := range m.listeners {
+		listener(e)
+	}
+}
+
+// emit sends the event to the map the city belongs to, or keeps it in the group the city moves with.
+// Cities wired by hand have no map, and their events are dropped.
+func (c *City) emit(e Event) {
+	if c.shard != nil {
+		c.shard.emit(e)
+	} else if c.gameMap != nil {
+		c.gameMap.emit(e)
+	}
+}
+
+// alien returns the name of i-th alien involved, its number unless named otherwise
+func (e Event) alien(i int) string {
+	if i < len(e.Names) {
+		return e.Names[i]
+	}
+	return strconv.Itoa(e.Aliens[i])
+}
+
+// alienNames prints names of aliens involved like `1, 2 and 3`
+func alienNames(e Event) string {
+	var texts []string
+	for i := range e.Aliens {
+		texts = append(texts, e.alien(i))
+	}
+	if len(texts) < 2 {
+		return strings.Join(texts, "")
+	}
+	return strings.Join(texts[:len(texts)-1], ", ") + " and " + texts[len(texts)-1]
+}
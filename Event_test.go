@@ -0,0 +1,95 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEvent_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{
+			name:  "City destroyed by aliens",
+			event: Event{Type: CityDestroyed, City: "city1", Aliens: []int{1, 2}},
+			want:  "City city1 have been destroyed by alien 1 and 2!",
+		},
+		{
+			name:  "City destroyed by gathering",
+			event: Event{Type: CityDestroyed, City: "city1", Aliens: []int{1, 2, 3}},
+			want:  "City city1 have been destroyed by aliens 1, 2 and 3!",
+		},
+		{
+			name:  "City destroyed by named aliens",
+			event: Event{Type: CityDestroyed, City: "city1", Aliens: []int{1, 2, 3}, Names: []string{"Zorg", "Blip", "3"}},
+			want:  "City city1 have been destroyed by aliens Zorg, Blip and 3!",
+		},
+		{
+			name:  "City damaged by aliens",
+			event: Event{Type: CityDamaged, City: "city1", Aliens: []int{1, 2}, HitPoints: 3},
+			want:  "City city1 have been damaged by alien 1 and 2, 3 hit points left!",
+		},
+		{
+			name:  "Road destroyed by aliens",
+			event: Event{Type: RoadDestroyed, From: "city1", To: "city2", Aliens: []int{1, 2}},
+			want:  "Road between city1 and city2 have been destroyed by alien 1 and 2!",
+		},
+		{
+			name:  "Alien landed",
+			event: Event{Type: AlienSpawned, City: "city1", Aliens: []int{4}},
+			want:  "Alien 4 landed in city city1!",
+		},
+		{
+			name:  "City rebuilt",
+			event: Event{Type: CityRebuilt, City: "city1"},
+			want:  "City city1 have been rebuilt!",
+		},
+		{
+			name:  "Road rebuilt",
+			event: Event{Type: RoadRebuilt, From: "city1", To: "city2"},
+			want:  "Road between city1 and city2 have been rebuilt!",
+		},
+		{
+			name:  "Alien exhausted",
+			event: Event{Type: AlienExhausted, City: "city1", Aliens: []int{4}},
+			want:  "Alien 4 died of exhaustion in city city1!",
+		},
+		{
+			name:  "Alien expired",
+			event: Event{Type: AlienExpired, City: "city1", Aliens: []int{4}},
+			want:  "Alien 4 died of old age in city city1!",
+		},
+		{
+			name:  "Road failure",
+			event: Event{Type: RoadDestroyed, From: "city1", To: "city2"},
+			want:  "Road between city1 and city2 have been destroyed!",
+		},
+		{
+			name:  "Alien killed on road",
+			event: Event{Type: AlienKilled, From: "city1", To: "city2", Aliens: []int{3}},
+			want:  "Alien 3 was killed on the road between city1 and city2!",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.event.String())
+		})
+	}
+}
+
+func TestGameMap_OnEvent(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar")
+	var got []Event
+	m.OnEvent(func(e Event) {
+		got = append(got, e)
+	})
+	attacker := &Alien{Number: 1, Alive: true, Strength: 1, Health: 1}
+	m.cities["Foo"].Aliens = []*Alien{attacker}
+	m.cities["Bar"].Aliens = []*Alien{{Number: 2, Alive: true, Strength: 1, Health: 1}}
+	m.tick = 5
+	m.cities["Foo"].AlienMigrate(attacker, m.cities["Bar"])
+	assert.Equal(t, []Event{{Tick: 5, Type: CityDestroyed, City: "Bar", Aliens: []int{1, 2}}}, got)
+	assert.Equal(t, got, m.Events())
+}
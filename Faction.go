@@ -0,0 +1,98 @@
+package alien_invastion
+
+import "fmt"
+
+// SameFactionPolicy decides what happens when an alien enters a city held by an alien of the same faction
+type SameFactionPolicy int
+
+const (
+	// FactionCoexist aliens never fight their own faction, they share the city if its capacity allows, or the alien entering gives way
+	FactionCoexist SameFactionPolicy = iota
+	// FactionMerge aliens join forces, the alien entering is absorbed by the first alien in the city
+	FactionMerge
+)
+
+func (p SameFactionPolicy) String() string {
+	switch p {
+	case FactionCoexist:
+		return "coexist"
+	case FactionMerge:
+		return "merge"
+	default:
+		return "unknown"
+	}
+}
+
+// SameFactionPolicyFromString returns the policy by name : coexist or merge
+func SameFactionPolicyFromString(name string) (SameFactionPolicy, error) {
+	switch name {
+	case "coexist":
+		return FactionCoexist, nil
+	case "merge":
+		return FactionMerge, nil
+	default:
+		return FactionCoexist, fmt.Errorf("unknown same faction policy %s", name)
+	}
+}
+
+// IsRival tells if two aliens should fight. Invaders and defenders always fight, defenders never fight each other.
+// Invaders without faction fight every invader.
+func (a *Alien) IsRival(other *Alien) bool {
+	if a.Side != other.Side {
+		return true
+	}
+	if a.Side == Defenders {
+		return false
+	}
+	return a.Faction == "" || a.Faction != other.Faction
+}
+
+// AssignFactions puts aliens into factions in turn, so factions have almost the same size
+func AssignFactions(aliens []*Alien, factions []string) {
+	if len(factions) == 0 {
+		return
+	}
+	for i, alien := range aliens {
+		alien.Faction = factions[i%len(factions)]
+	}
+}
+
+// SetSameFactionPolicy changes what happens when aliens of the same faction meet, FactionCoexist by default
+func (m *GameMap) SetSameFactionPolicy(policy SameFactionPolicy) {
+	m.sameFactionPolicy = policy
+}
+
+// FactionControl returns which faction controls which surviving cities, a city is controlled by the faction entered it last
+func (m *GameMap) FactionControl() map[string][]string {
+	control := make(map[string][]string)
+	for _, city := range m.cityList {
+		if city != nil && city.Exists && city.ControlledBy != "" {
+			control[city.ControlledBy] = append(control[city.ControlledBy], city.Name)
+		}
+	}
+	return control
+}
+
+// claim marks the city controlled by faction of the alien
+func (c *City) claim(alien *Alien) {
+	if alien.Faction != "" {
+		c.ControlledBy = alien.Faction
+	}
+}
+
+// meetFriend handles an alien entering a city held by aliens of the same faction. entered is false if it gives way to a full city.
+func (c *City) meetFriend(alien, friend *Alien) (entered bool) {
+	if c.gameMap == nil || c.gameMap.sameFactionPolicy == FactionCoexist || alien.Side == Defenders {
+		if c.IsFull() {
+			return false
+		}
+		c.join(alien)
+		c.gather()
+		return true
+	}
+	friend.Strength += alien.Strength
+	friend.Health += alien.Health
+	alien.Alive = false
+	c.emit(Event{Type: AliensMerged, City: c.Name, Aliens: []int{alien.Number, friend.Number}})
+	return true
+}
@@ -0,0 +1,152 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAlien_IsRival(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     string
+		b     string
+		rival bool
+	}{
+		{name: "No faction fights everyone", a: "", b: "", rival: true},
+		{name: "No faction against faction", a: "", b: "red", rival: true},
+		{name: "Faction against no faction", a: "red", b: "", rival: true},
+		{name: "Rival factions", a: "red", b: "blue", rival: true},
+		{name: "Same faction", a: "red", b: "red", rival: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.rival, (&Alien{Faction: tt.a}).IsRival(&Alien{Faction: tt.b}))
+		})
+	}
+}
+
+func TestAssignFactions(t *testing.T) {
+	ids := &AlienIDs{}
+	aliens := []*Alien{ids.NewAlien(), ids.NewAlien(), ids.NewAlien()}
+	AssignFactions(aliens, []string{"red", "blue"})
+	assert.Equal(t, "red", aliens[0].Faction)
+	assert.Equal(t, "blue", aliens[1].Faction)
+	assert.Equal(t, "red", aliens[2].Faction)
+	// Nothing to assign
+	AssignFactions(aliens, nil)
+	assert.Equal(t, "red", aliens[0].Faction)
+}
+
+func TestSameFactionPolicyFromString(t *testing.T) {
+	policy, err := SameFactionPolicyFromString("merge")
+	assert.NoError(t, err)
+	assert.Equal(t, FactionMerge, policy)
+	policy, err = SameFactionPolicyFromString("coexist")
+	assert.NoError(t, err)
+	assert.Equal(t, FactionCoexist, policy)
+	_, err = SameFactionPolicyFromString("betray")
+	assert.Error(t, err)
+}
+
+func TestCity_AlienMigrateWithFactions(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         SameFactionPolicy
+		capacity       int
+		attacker       string
+		defender       string
+		wantFrom       bool
+		wantOccupants  []int
+		wantStrength   int
+		wantExists     bool
+		wantControlled string
+	}{
+		{
+			name:           "Friends coexist",
+			policy:         FactionCoexist,
+			capacity:       1,
+			attacker:       "red",
+			defender:       "red",
+			wantFrom:       true,
+			wantOccupants:  []int{2},
+			wantStrength:   1,
+			wantExists:     true,
+			wantControlled: "red",
+		},
+		{
+			name:           "Friends share the city",
+			policy:         FactionCoexist,
+			capacity:       2,
+			attacker:       "red",
+			defender:       "red",
+			wantFrom:       false,
+			wantOccupants:  []int{2, 1},
+			wantStrength:   1,
+			wantExists:     true,
+			wantControlled: "red",
+		},
+		{
+			name:           "Friends merge",
+			policy:         FactionMerge,
+			capacity:       1,
+			attacker:       "red",
+			defender:       "red",
+			wantFrom:       false,
+			wantOccupants:  []int{2},
+			wantStrength:   2,
+			wantExists:     true,
+			wantControlled: "red",
+		},
+		{
+			name:           "Rivals fight",
+			policy:         FactionMerge,
+			capacity:       2,
+			attacker:       "blue",
+			defender:       "red",
+			wantFrom:       false,
+			wantExists:     false,
+			wantControlled: "red",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("Foo east=Bar")
+			m.SetSameFactionPolicy(tt.policy)
+			m.SetCityCapacity(tt.capacity)
+			attacker := &Alien{Number: 1, Alive: true, Strength: 1, Health: 1, Faction: tt.attacker}
+			defender := &Alien{Number: 2, Alive: true, Strength: 1, Health: 1, Faction: tt.defender}
+			m.cities["Foo"].Aliens = []*Alien{attacker}
+			m.cities["Bar"].Aliens = []*Alien{defender}
+			m.cities["Bar"].claim(defender)
+			m.cities["Foo"].AlienMigrate(attacker, m.cities["Bar"])
+			assert.Equal(t, tt.wantFrom, len(m.cities["Foo"].Aliens) > 0)
+			var occupants []int
+			for _, alien := range m.cities["Bar"].Aliens {
+				occupants = append(occupants, alien.Number)
+			}
+			assert.Equal(t, tt.wantOccupants, occupants)
+			if len(occupants) > 0 {
+				assert.Equal(t, tt.wantStrength, m.cities["Bar"].Aliens[0].Strength)
+			}
+			assert.Equal(t, tt.wantExists, m.cities["Bar"].Exists)
+			assert.Equal(t, tt.wantControlled, m.cities["Bar"].ControlledBy)
+		})
+	}
+}
+
+func TestGameMap_FactionControl(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar north=Baz\nBaz north=Bee")
+	m.SetCombatResolver(fixedResolver(AttackerWins))
+	red := &Alien{Number: 1, Alive: true, Strength: 1, Health: 1, Faction: "red"}
+	blue := &Alien{Number: 2, Alive: true, Strength: 1, Health: 1, Faction: "blue"}
+	m.cities["Foo"].join(red)
+	m.cities["Bar"].join(blue)
+	m.cities["Baz"].claim(blue)
+	m.cities["Baz"].Exists = false
+	assert.Equal(t, map[string][]string{"red": {"Foo"}, "blue": {"Bar"}}, m.FactionControl())
+
+	// Red takes fortified Bar over
+	m.cities["Bar"].HitPoints = 10
+	m.cities["Foo"].AlienMigrate(red, m.cities["Bar"])
+	assert.Equal(t, map[string][]string{"red": {"Foo", "Bar"}}, m.FactionControl())
+}
@@ -0,0 +1,109 @@
+package alien_invastion
+
+import "fmt"
+
+// Exhaustion decides what happens to an alien too tired to move on
+type Exhaustion int
+
+const (
+	// ExhaustedStay the alien stays in the city until rested
+	ExhaustedStay Exhaustion = iota
+	// ExhaustedDie the alien dies
+	ExhaustedDie
+)
+
+func (e Exhaustion) String() string {
+	switch e {
+	case ExhaustedStay:
+		return "stay"
+	case ExhaustedDie:
+		return "die"
+	default:
+		return "unknown"
+	}
+}
+
+// ExhaustionFromString returns the exhaustion rule by name : stay or die
+func ExhaustionFromString(name string) (Exhaustion, error) {
+	switch name {
+	case "stay":
+		return ExhaustedStay, nil
+	case "die":
+		return ExhaustedDie, nil
+	default:
+		return ExhaustedStay, fmt.Errorf("unknown exhaustion rule %s", name)
+	}
+}
+
+// FatigueRules limits how far aliens go. Every move tires the alien, and every tick staying in a city rests it.
+// The zero value disables all limits.
+type FatigueRules struct {
+	Stamina    int // Fatigue an alien takes before being exhausted, 0 means aliens never tire
+	MoveCost   int // Fatigue of every move, 1 if 0
+	Rest       int // Fatigue recovered in every tick staying in a city
+	Lifespan   int // Steps an alien lives, 0 means forever
+	Exhaustion Exhaustion
+}
+
+// SetFatigueRules changes how aliens tire and age, no limit by default
+func (m *GameMap) SetFatigueRules(rules FatigueRules) {
+	m.fatigue = rules
+}
+
+func (r FatigueRules) moveCost() int {
+	if r.MoveCost < 1 {
+		return 1
+	}
+	return r.MoveCost
+}
+
+// fatigueRules returns rules of the map the city belongs to, cities wired by hand have no limit
+func (c *City) fatigueRules() FatigueRules {
+	if c.gameMap == nil {
+		return FatigueRules{}
+	}
+	return c.gameMap.fatigue
+}
+
+// aged kills the alien once it lives longer than its lifespan
+func (a *Alien) aged(from *City) bool {
+	rules := from.fatigueRules()
+	if rules.Lifespan <= 0 || a.Steps <= rules.Lifespan {
+		return false
+	}
+	a.Alive = false
+	from.leave(a)
+	from.emit(Event{Type: AlienExpired, City: from.Name, Aliens: []int{a.Number}})
+	return true
+}
+
+// tired tells if the alien is too tired to move on. An exhausted alien dies, or rests instead, depending on rules.
+func (a *Alien) tired(from *City) bool {
+	rules := from.fatigueRules()
+	if rules.Stamina <= 0 || a.Fatigue+rules.moveCost() <= rules.Stamina {
+		return false
+	}
+	if rules.Exhaustion == ExhaustedDie {
+		a.Alive = false
+		from.leave(a)
+		from.emit(Event{Type: AlienExhausted, City: from.Name, Aliens: []int{a.Number}})
+		return true
+	}
+	a.rest(from)
+	return true
+}
+
+// tire adds fatigue of a move
+func (a *Alien) tire(from *City) {
+	if rules := from.fatigueRules(); rules.Stamina > 0 {
+		a.Fatigue += rules.moveCost()
+	}
+}
+
+// rest recovers fatigue of the alien staying in a city
+func (a *Alien) rest(from *City) {
+	a.Fatigue -= from.fatigueRules().Rest
+	if a.Fatigue < 0 {
+		a.Fatigue = 0
+	}
+}
@@ -0,0 +1,102 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExhaustionFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Exhaustion
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "stay", want: ExhaustedStay, wantErr: assert.NoError},
+		{name: "die", want: ExhaustedDie, wantErr: assert.NoError},
+		{name: "sleep", want: ExhaustedStay, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExhaustionFromString(tt.name)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAlien_MoveWithFatigue(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       FatigueRules
+		ticks       int
+		wantSteps   []string // City of the alien after every tick
+		wantAlive   bool
+		wantFatigue int
+		wantEvent   EventType
+	}{
+		{
+			name:        "Never tires",
+			rules:       FatigueRules{},
+			ticks:       3,
+			wantSteps:   []string{"Bar", "Foo", "Bar"},
+			wantAlive:   true,
+			wantFatigue: 0,
+		},
+		{
+			name:        "Rests when tired",
+			rules:       FatigueRules{Stamina: 2, Rest: 1},
+			ticks:       5,
+			wantSteps:   []string{"Bar", "Foo", "Foo", "Bar", "Bar"},
+			wantAlive:   true,
+			wantFatigue: 1,
+		},
+		{
+			name:        "Expensive moves",
+			rules:       FatigueRules{Stamina: 4, MoveCost: 2, Rest: 2},
+			ticks:       4,
+			wantSteps:   []string{"Bar", "Foo", "Foo", "Bar"},
+			wantAlive:   true,
+			wantFatigue: 4,
+		},
+		{
+			name:        "Dies of exhaustion",
+			rules:       FatigueRules{Stamina: 2, Exhaustion: ExhaustedDie},
+			ticks:       3,
+			wantSteps:   []string{"Bar", "Foo", ""},
+			wantAlive:   false,
+			wantFatigue: 2,
+			wantEvent:   AlienExhausted,
+		},
+		{
+			name:      "Dies of old age",
+			rules:     FatigueRules{Lifespan: 2},
+			ticks:     3,
+			wantSteps: []string{"Bar", "Foo", ""},
+			wantAlive: false,
+			wantEvent: AlienExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("Foo north=Bar")
+			m.SetFatigueRules(tt.rules)
+			alien := &Alien{Number: 0, Alive: true}
+			m.cities["Foo"].Aliens = []*Alien{alien}
+			for i := 0; i < tt.ticks; i++ {
+				m.Update()
+				var at string
+				for _, city := range m.cityList {
+					if len(city.Aliens) > 0 {
+						at = city.Name
+					}
+				}
+				assert.Equal(t, tt.wantSteps[i], at, "tick %d", i+1)
+			}
+			assert.Equal(t, tt.wantAlive, alien.Alive)
+			assert.Equal(t, tt.wantFatigue, alien.Fatigue)
+			if !tt.wantAlive {
+				assert.Equal(t, []Event{{Tick: tt.ticks, Type: tt.wantEvent, City: "Foo", Aliens: []int{0}}}, m.Events())
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package alien_invastion
+
+import "fmt"
+
+// Game is a simulation set up from a scenario, with all units took part in it
+type Game struct {
+	Scenario  *Scenario
+	Map       *GameMap
+	Aliens    []*Alien // Aliens at start and landed later
+	Defenders []*Alien
+}
+
+// NewGame parses the map of the scenario, applies its rules, and places aliens and defenders
+func (s *Scenario) NewGame() (*Game, error) {
+	directions, err := DirectionSetFromString(s.Directions)
+	if err != nil {
+		return nil, err
+	}
+	parser := StreamParser{Directions: directions, Graph: s.Graph, Compact: s.Compact}
+	gameMap, errors := parser.ParseFile(s.Map)
+	if len(errors) > 0 {
+		return nil, fmt.Errorf("%v", errors)
+	}
+	if s.Actors && s.Workers > 0 {
+		return nil, fmt.Errorf("workers and actors can't be used together")
+	}
+	if s.Termination.MaxSteps < 0 || s.Termination.MaxTicks < 0 {
+		return nil, fmt.Errorf("max steps and max ticks can't be negative, 0 means no limit")
+	}
+	if s.Aliens.Strength < 0 {
+		return nil, fmt.Errorf("alien strength can't be negative, got %d", s.Aliens.Strength)
+	}
+	if s.Aliens.Health < 1 {
+		return nil, fmt.Errorf("alien health should be at least 1, got %d", s.Aliens.Health)
+	}
+	if s.Seed != nil {
+		gameMap.SetSeed(*s.Seed)
+	}
+	if err = s.Rules.apply(gameMap); err != nil {
+		return nil, err
+	}
+	gameMap.SetMaxSteps(s.Termination.MaxSteps)
+	gameMap.SetMaxTicks(s.Termination.MaxTicks)
+	gameMap.SetWorkers(s.Workers)
+	gameMap.SetStackedPlacement(s.Aliens.Stack)
+	placement, err := PlacementStrategyFromString(s.Aliens.Placement)
+	if err != nil {
+		return nil, err
+	}
+	gameMap.SetPlacementStrategy(placement)
+	if len(s.Aliens.Names) > 0 {
+		gameMap.AlienIDs().SetNaming(NameList(s.Aliens.Names))
+	}
+
+	g := &Game{Scenario: s, Map: gameMap, Aliens: make([]*Alien, 0)}
+	strategy, err := MovementStrategyFromString(s.Aliens.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	// Aliens landing later join factions in turn as well
+	newAlien := func() *Alien {
+		alien := gameMap.AlienIDs().NewAlien()
+		alien.Strength = s.Aliens.Strength
+		alien.Health = s.Aliens.Health
+		alien.Strategy = strategy
+		if len(s.Aliens.Factions) > 0 {
+			alien.Faction = s.Aliens.Factions[len(g.Aliens)%len(s.Aliens.Factions)]
+		}
+		g.Aliens = append(g.Aliens, alien)
+		return alien
+	}
+	var waves []Wave
+	for _, spawn := range s.Spawn {
+		wave, err := ParseWave(spawn)
+		if err != nil {
+			return nil, err
+		}
+		waves = append(waves, wave)
+	}
+	if err = gameMap.SetSpawnSchedule(waves, newAlien); err != nil {
+		return nil, err
+	}
+
+	defenderStrategy, err := MovementStrategyFromString(s.Defenders.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	g.Defenders = gameMap.PlaceDefenders(defenderStrategy)
+
+	// Cities are taken by garrisons and placements first, then by units placed by the placement strategy
+	placed := 0
+	for _, placement := range s.Aliens.Placements {
+		for i := 0; i < placement.Count; i++ {
+			if placed++; placed > s.Aliens.Count {
+				return nil, fmt.Errorf("more aliens placed than %d aliens", s.Aliens.Count)
+			}
+			if err = gameMap.PlaceAlien(newAlien(), placement.City); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var aliens []*Alien
+	for placed < s.Aliens.Count {
+		aliens = append(aliens, newAlien())
+		placed++
+	}
+	// Indexes of At count aliens of placements as well
+	at := make(map[int]string)
+	offset := s.Aliens.Count - len(aliens)
+	for index, city := range s.Aliens.At {
+		if index >= 0 && index < offset {
+			return nil, fmt.Errorf("alien %d is already placed by placements", index)
+		}
+		at[index-offset] = city
+	}
+	if _, err = gameMap.checkPlacements(len(aliens), at); err != nil {
+		return nil, err
+	}
+	var others []*Alien
+	for i, alien := range aliens {
+		if city, found := at[i]; found {
+			if err = gameMap.PlaceAlien(alien, city); err != nil {
+				return nil, err
+			}
+		} else {
+			others = append(others, alien)
+		}
+	}
+
+	var defenders []*Alien
+	for i := 0; i < s.Defenders.Count; i++ {
+		defenders = append(defenders, gameMap.AlienIDs().NewDefender(defenderStrategy))
+	}
+	g.Defenders = append(g.Defenders, defenders...)
+	if err = gameMap.AssignAliens(defenders); err != nil {
+		return nil, err
+	}
+	if err = gameMap.AssignAliens(others); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// apply sets the rules to the map
+func (r Rules) apply(gameMap *GameMap) error {
+	resolver, err := CombatResolverFromString(r.Combat, r.RetreatChance)
+	if err != nil {
+		return err
+	}
+	gameMap.SetCombatResolver(resolver)
+	policy, err := SameFactionPolicyFromString(r.SameFaction)
+	if err != nil {
+		return err
+	}
+	gameMap.SetSameFactionPolicy(policy)
+	exhaustion, err := ExhaustionFromString(r.Fatigue.Exhaustion)
+	if err != nil {
+		return err
+	}
+	gameMap.SetFatigueRules(FatigueRules{
+		Stamina:    r.Fatigue.Stamina,
+		MoveCost:   r.Fatigue.MoveCost,
+		Rest:       r.Fatigue.Rest,
+		Lifespan:   r.Fatigue.Lifespan,
+		Exhaustion: exhaustion,
+	})
+	gameMap.SetCityCapacity(r.Capacity)
+	gameMap.SetGatherThreshold(r.Gather)
+	gameMap.SetRoadFailureRate(r.RoadFailureRate)
+	gameMap.SetRebuildDelay(r.RebuildDelay)
+	return nil
+}
+
+// Run updates the map until the game ends
+func (g *Game) Run() {
+	if g.Scenario.Actors {
+		g.Map.RunActors()
+		return
+	}
+	for g.Map.Update() {
+	}
+}
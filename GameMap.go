@@ -2,8 +2,12 @@ package alien_invastion
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Direction int
@@ -13,6 +17,12 @@ const (
 	West
 	South
 	East
+	NorthEast
+	NorthWest
+	SouthEast
+	SouthWest
+	Up
+	Down
 
 	DirectionSize // must be last, if we need more direction please add in front of it.
 	Invalid
@@ -28,6 +38,18 @@ func (d Direction) GetOpposite() Direction {
 		return West
 	case West:
 		return East
+	case NorthEast:
+		return SouthWest
+	case SouthWest:
+		return NorthEast
+	case NorthWest:
+		return SouthEast
+	case SouthEast:
+		return NorthWest
+	case Up:
+		return Down
+	case Down:
+		return Up
 	default:
 		return Invalid
 	}
@@ -43,6 +65,18 @@ func (d Direction) String() string {
 		return "east"
 	case West:
 		return "west"
+	case NorthEast:
+		return "northeast"
+	case NorthWest:
+		return "northwest"
+	case SouthEast:
+		return "southeast"
+	case SouthWest:
+		return "southwest"
+	case Up:
+		return "up"
+	case Down:
+		return "down"
 	default:
 		return "invalid"
 	}
@@ -58,12 +92,79 @@ func DirectionFromString(from string) Direction {
 		return South
 	case "east":
 		return East
+	case "northeast":
+		return NorthEast
+	case "northwest":
+		return NorthWest
+	case "southeast":
+		return SouthEast
+	case "southwest":
+		return SouthWest
+	case "up":
+		return Up
+	case "down":
+		return Down
 	default:
 		// This will make panic or error
 		return Invalid
 	}
 }
 
+// DirectionSet is the set of directions a map accepts. Directions are dumped in the order they are listed.
+type DirectionSet struct {
+	Name       string
+	Directions []Direction
+}
+
+var (
+	// CompassDirections is the classic north/west/south/east set, and the default of every map
+	CompassDirections = &DirectionSet{Name: "compass", Directions: []Direction{North, West, South, East}}
+	// EightWayDirections adds diagonals to the compass
+	EightWayDirections = &DirectionSet{Name: "8way", Directions: []Direction{North, West, South, East, NorthEast, NorthWest, SouthEast, SouthWest}}
+	// CompassVerticalDirections is the compass with up/down links for multi-level maps
+	CompassVerticalDirections = &DirectionSet{Name: "compass3d", Directions: []Direction{North, West, South, East, Up, Down}}
+	// EightWayVerticalDirections is the 8-way compass with up/down links for multi-level maps
+	EightWayVerticalDirections = &DirectionSet{Name: "8way3d", Directions: []Direction{North, West, South, East, NorthEast, NorthWest, SouthEast, SouthWest, Up, Down}}
+)
+
+// NewDirectionSet creates a custom direction set. Every direction's opposite must be in the set as well, or aliens could walk into a road they can't describe.
+func NewDirectionSet(name string, directions ...Direction) (*DirectionSet, error) {
+	set := &DirectionSet{Name: name, Directions: directions}
+	for _, d := range directions {
+		if d < 0 || d >= DirectionSize {
+			return nil, fmt.Errorf("direction set %s contains invalid direction %d", name, d)
+		}
+		if !set.Contains(d.GetOpposite()) {
+			return nil, fmt.Errorf("direction set %s contains %s but not its opposite %s", name, d, d.GetOpposite())
+		}
+	}
+	return set, nil
+}
+
+// DirectionSetFromString returns one of the built-in direction sets by its name
+func DirectionSetFromString(name string) (*DirectionSet, error) {
+	for _, set := range []*DirectionSet{CompassDirections, EightWayDirections, CompassVerticalDirections, EightWayVerticalDirections} {
+		if strings.ToLower(name) == set.Name {
+			return set, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown direction set %s", name)
+}
+
+// Contains tells if the direction is part of this set
+func (s *DirectionSet) Contains(d Direction) bool {
+	for _, direction := range s.Directions {
+		if direction == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DirectionSet) String() string {
+	return s.Name
+}
+
 type Neighborhoods []*City
 
 func (n Neighborhoods) String() string {
@@ -79,28 +180,87 @@ func (n Neighborhoods) String() string {
 type City struct {
 	Name          string
 	Neighborhoods Neighborhoods
+	Roads         []*Road
 	Exists        bool
-	AlienInCity   *Alien
+	Aliens        []*Alien
+	Capacity      int    // Max aliens in the city, 0 means capacity of the map
+	Garrison      int    // Defenders placed in the city at start, see GameMap.PlaceDefenders
+	DestroyedAt   int    // Tick the city has been destroyed, see GameMap.SetRebuildDelay
+	HitPoints     int    // City is destroyed once hit points drop to 0
+	Defense       int    // Damage absorbed in every fight
+	hitPoints     int    // Hit points declared by the map, 0 if not declared, see City.rebuilt
+	ControlledBy  string // Faction of the last alien entered, empty if none
+	gameMap       *GameMap
+	index         int    // Position in cities of the map
+	enrolled      bool   // In roster of cities holding units
+	ruined        bool   // In roster of destroyed cities
+	wired         bool   // Roads are created from the compact map, see City.wire
+	shard         *shard // Group the city moves with in current tick, see GameMap.SetWorkers
 }
 
 //Should be private, and external call will only use name as index
 func newCity(name string) *City {
-	return &City{Name: name, Neighborhoods: make(Neighborhoods, DirectionSize), Exists: true}
+	return &City{Name: name, Neighborhoods: make(Neighborhoods, DirectionSize), Exists: true, HitPoints: 1}
+}
+
+// declaration prints the city name, and its hit points and defense if they are not default
+func (c *City) declaration() string {
+	ret := c.Name
+	if c.HitPoints > 1 {
+		ret += fmt.Sprintf(" hp=%d", c.HitPoints)
+	}
+	if c.Defense > 0 {
+		ret += fmt.Sprintf(" defense=%d", c.Defense)
+	}
+	if c.Capacity > 0 {
+		ret += fmt.Sprintf(" capacity=%d", c.Capacity)
+	}
+	if c.Garrison > 0 {
+		ret += fmt.Sprintf(" defenders=%d", c.Garrison)
+	}
+	return ret
 }
 
-// AlienMigrate Moves alien to new city, and decide if needs battle(and destroy the city as well)
-func (c *City) AlienMigrate(to *City) {
-	alien := c.AlienInCity
-	if alien == nil || c.Exists == false {
+// AlienMigrate Moves an alien of the city to new city, and decide if needs battle(and destroy the city as well)
+// An alien retreating from the battle, or giving way to friends, stays in current city
+func (c *City) AlienMigrate(alien *Alien, to *City) {
+	if c.Exists == false || !c.leave(alien) {
 		return
 	}
+	if !to.alienArrive(alien) {
+		c.admit(alien)
+	}
+}
 
-	if to.AlienInCity != nil {
-		fmt.Printf("City %s have been destroyed by alien %v and %v!\n", to.Name, alien.Number, to.AlienInCity.Number)
-		to.Exists = false
+// alienArrive puts an alien into the city, and fight if there is a rival in it. Aliens arriving in a destroyed city are stuck in it.
+// entered is false if the alien retreats from the fight, or gives way to friends.
+func (c *City) alienArrive(alien *Alien) (entered bool) {
+	if !c.Exists {
+		c.admit(alien)
+		return true
+	}
+	for _, other := range c.Aliens {
+		if alien.IsRival(other) {
+			return c.fight(alien, other)
+		}
+	}
+	if len(c.Aliens) > 0 {
+		return c.meetFriend(alien, c.Aliens[0])
 	}
-	to.AlienInCity = alien
-	c.AlienInCity = nil
+	c.join(alien)
+	c.gather()
+	return true
+}
+
+// random returns dice of the turn being played, by the group the city moves with or by the simulation, cities wired by hand have their own
+func (c *City) random() *rand.Rand {
+	if c.shard != nil {
+		return c.shard.dice.rng
+	}
+	if c.gameMap != nil {
+		return c.gameMap.random()
+	}
+	return rand.New(rand.NewSource(rand.Int63()))
 }
 
 // IsIsolatedOrDestroyed means that the city can't perform any moving action
@@ -108,47 +268,224 @@ func (c *City) IsIsolatedOrDestroyed() bool {
 	if c.Exists == false {
 		return true
 	}
-	for _, n := range c.Neighborhoods {
-		if n != nil && n.Exists {
+	for _, exit := range c.Exits() {
+		if exit.To.Exists {
 			return false
 		}
 	}
 	return true
 }
 
+// GameMap is the world being invaded. A GameMap is built, set up and played by one goroutine.
+// Other goroutines, like an HTTP handler showing the game, may call Snapshot and View at any time. They wait for
+// Update, AssignAliens, AssignAliensAt, PlaceAlien, PlaceDefenders and DestroyRoad to return, so these may run meanwhile.
+// No other method is safe to call from other goroutines, reading ones included, since reading creates cities
+// of compact maps and prunes rosters. Listeners, strategies and resolvers run inside Update, they read the map directly
+// and must not call Snapshot or View.
 type GameMap struct {
-	cities map[string]*City
+	cities            map[string]*City
+	cityList          []*City // Cities in the order they are created, keeps simulation reproducible. Nil for cities of a compact map not touched yet.
+	roads             []*Road
+	directions        *DirectionSet
+	graph             bool
+	tick              int
+	transits          []*transit
+	roadFailureRate   float64
+	events            []Event
+	listeners         []func(Event)
+	rng               *rand.Rand
+	dice              *dice // Dice of the turn played by the classic engine, nil out of turns
+	combatResolver    CombatResolver
+	sameFactionPolicy SameFactionPolicy
+	cityCapacity      int // 0 means unlimited
+	gatherThreshold   int
+	stackedPlacement  bool
+	defended          bool // Defenders have been placed, game ends once they are all killed
+	waves             []Wave
+	newAlien          func() *Alien
+	rebuildDelay      int
+	fatigue           FatigueRules
+	maxSteps          int
+	maxTicks          int
+	placement         PlacementStrategy
+	roster            roster
+	compact           *compactMap
+	workers           int
+	stage             *stage // Goroutines of aliens and cities, see RunActors
+	ids               *AlienIDs
+	mu                sync.Mutex // Held by ticks and changes of units, see Snapshot
 }
 
 func NewGameMap() *GameMap {
+	return NewGameMapWithDirections(CompassDirections)
+}
+
+// NewGameMapWithDirections creates a map accepting only the given direction set
+func NewGameMapWithDirections(directions *DirectionSet) *GameMap {
 	return &GameMap{
-		cities: make(map[string]*City),
+		cities:       make(map[string]*City),
+		directions:   directions,
+		rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		cityCapacity: 1,
+		maxSteps:     10000,
+		ids:          &AlienIDs{},
+	}
+}
+
+// NewGraphGameMap creates a map without directions, cities are linked by named or anonymous roads and may have any number of neighbors
+func NewGraphGameMap() *GameMap {
+	m := NewGameMapWithDirections(nil)
+	m.graph = true
+	return m
+}
+
+// SetMaxSteps ends the game once an alien goes more than given steps, 10000 by default, 0 means no limit like SetMaxTicks
+func (m *GameMap) SetMaxSteps(steps int) {
+	if steps <= 0 {
+		steps = math.MaxInt
+	}
+	m.maxSteps = steps
+}
+
+// SetMaxTicks ends the game after given ticks, 0 means no limit
+func (m *GameMap) SetMaxTicks(ticks int) {
+	m.maxTicks = ticks
+}
+
+// IsGraph tells if the map is a graph map, see NewGraphGameMap
+func (m *GameMap) IsGraph() bool {
+	return m.graph
+}
+
+// SetSeed makes the simulation reproducible, same map with same seed always ends in the same way
+func (m *GameMap) SetSeed(seed int64) {
+	m.rng = rand.New(rand.NewSource(seed))
+}
+
+// random returns dice of the turn being played, or RNG of the simulation out of turns
+func (m *GameMap) random() *rand.Rand {
+	if m.dice != nil {
+		return m.dice.rng
 	}
+	return m.Rand()
+}
+
+// Rand returns RNG of the simulation, every random decision should be made by it
+func (m *GameMap) Rand() *rand.Rand {
+	if m.rng == nil {
+		m.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return m.rng
+}
+
+// Directions returns the direction set of the map, compass if not specified
+func (m *GameMap) Directions() *DirectionSet {
+	if m.directions == nil {
+		return CompassDirections
+	}
+	return m.directions
 }
 
 // UpdateCityWithNeighborhood updateNeighborhoods update the city's neighborhood, if neighbor city is not exists, it will created
 func (m *GameMap) UpdateCityWithNeighborhood(name string, direction Direction, neighborhoodCityName string) error {
+	return m.updateCityWithRoad(name, direction, neighborhoodCityName, false)
+}
+
+// UpdateCityWithOneWayRoad works like UpdateCityWithNeighborhood, but the road can only be taken from the city to its neighborhood
+func (m *GameMap) UpdateCityWithOneWayRoad(name string, direction Direction, neighborhoodCityName string) error {
+	return m.updateCityWithRoad(name, direction, neighborhoodCityName, true)
+}
+
+func (m *GameMap) updateCityWithRoad(name string, direction Direction, neighborhoodCityName string, oneWay bool) error {
+	if m.graph {
+		return fmt.Errorf("%s: graph map has no directions, use ConnectCities instead", name)
+	}
+	if !m.Directions().Contains(direction) {
+		return fmt.Errorf("%s: direction %s is not allowed in %s map", name, direction, m.Directions())
+	}
 	city := m.UpsertCity(name)
 	//No nil check because m.UpsertCity will be always exists
 	neighborhoodCity := m.UpsertCity(neighborhoodCityName)
+	if oneWay {
+		// The neighborhood doesn't lead back, so only this side must agree
+		if current := city.Neighborhoods[direction]; current != nil && current != neighborhoodCity {
+			return fmt.Errorf("%s's %s is %s, but declared as %s (conflict)", city.Name, direction, current.Name, neighborhoodCity.Name)
+		}
+	} else if opposite := neighborhoodCity.Neighborhoods[direction.GetOpposite()]; opposite != nil && opposite != city {
+		// For example, A's north is B, but B's south is not A
+		return fmt.Errorf("%s's %s is %s, but %s's %s is %s (conflict)", city.Name, direction, neighborhoodCity.Name, neighborhoodCity.Name, direction.GetOpposite(), opposite.Name)
+	}
+	if _, err := city.connect(neighborhoodCity, direction, "", oneWay); err != nil {
+		return err
+	}
 	city.Neighborhoods[direction] = neighborhoodCity
-	if neighborhoodCity.Neighborhoods[direction.GetOpposite()] == nil {
+	if !oneWay {
 		neighborhoodCity.Neighborhoods[direction.GetOpposite()] = city
-	} else {
-		if neighborhoodCity.Neighborhoods[direction.GetOpposite()] != city {
-			// For example, A's north is B, but B's south is not A
-			return fmt.Errorf("%s's %s is %s, but %s's %s is %s (conflict)", city.Name, direction, neighborhoodCity.Name, neighborhoodCity.Name, direction.GetOpposite(), neighborhoodCity.Neighborhoods[direction.GetOpposite()].Name)
-		}
 	}
 	return nil
 }
 
+// ConnectCities links two cities of a graph map with a road, creating cities if not exists. roadName can be empty for anonymous roads.
+func (m *GameMap) ConnectCities(name string, roadName string, neighborhoodCityName string) error {
+	return m.connectCities(name, roadName, neighborhoodCityName, false)
+}
+
+// ConnectCitiesOneWay works like ConnectCities, but the road can only be taken from the city to its neighborhood
+func (m *GameMap) ConnectCitiesOneWay(name string, roadName string, neighborhoodCityName string) error {
+	return m.connectCities(name, roadName, neighborhoodCityName, true)
+}
+
+func (m *GameMap) connectCities(name string, roadName string, neighborhoodCityName string, oneWay bool) error {
+	if !m.graph {
+		return fmt.Errorf("%s: compass map needs directions, use UpdateCityWithNeighborhood instead", name)
+	}
+	if name == neighborhoodCityName {
+		return fmt.Errorf("%s: road leads to itself", name)
+	}
+	city := m.UpsertCity(name)
+	neighborhoodCity := m.UpsertCity(neighborhoodCityName)
+	if road := city.roadTo(neighborhoodCity, Invalid); road != nil && roadName != "" && road.Name != "" && road.Name != roadName {
+		return fmt.Errorf("road between %s and %s is named %s, but declared as %s (conflict)", name, neighborhoodCityName, road.Name, roadName)
+	}
+	road, err := city.connect(neighborhoodCity, Invalid, roadName, oneWay)
+	if err != nil {
+		return err
+	}
+	if road.Name == "" {
+		road.Name = roadName
+	}
+	return nil
+}
+
+// SetRoadLength sets how many ticks are needed to walk through the road from the city in given direction. Use Invalid direction for graph maps.
+func (m *GameMap) SetRoadLength(name string, direction Direction, neighborhoodCityName string, length int) error {
+	city, neighborhoodCity := m.lookup(name), m.lookup(neighborhoodCityName)
+	if city == nil || neighborhoodCity == nil {
+		return fmt.Errorf("no road between %s and %s", name, neighborhoodCityName)
+	}
+	road := city.roadTo(neighborhoodCity, direction)
+	if road == nil {
+		return fmt.Errorf("no road between %s and %s", name, neighborhoodCityName)
+	}
+	if length < 1 {
+		return fmt.Errorf("road between %s and %s must be at least 1 long, got %d", name, neighborhoodCityName, length)
+	}
+	if road.Length != 0 && road.Length != length {
+		return fmt.Errorf("road between %s and %s is %d long, but declared as %d (conflict)", name, neighborhoodCityName, road.Length, length)
+	}
+	road.Length = length
+	return nil
+}
+
 // UpsertCity Create a city if not exists, and return the city
 func (m *GameMap) UpsertCity(name string) *City {
 	var city *City
-	if c, exists := m.cities[name]; !exists {
+	if c := m.lookup(name); c == nil {
 		city = newCity(name)
+		city.gameMap = m
+		city.index = len(m.cityList)
 		m.cities[name] = city
+		m.cityList = append(m.cityList, city)
 	} else {
 		city = c
 	}
@@ -157,19 +494,17 @@ func (m *GameMap) UpsertCity(name string) *City {
 
 // GetExistCity return the city, if not exists, return nil
 func (m *GameMap) GetExistCity(name string) *City {
-	if c, cityInMap := m.cities[name]; cityInMap {
-		if c.Exists {
-			return c
-		}
+	if c := m.lookup(name); c != nil && c.Exists {
+		return c
 	}
 	return nil
 }
 
 // destroyCity set city destroyed, error if not such city or already destroyed.
 func (m *GameMap) destroyCity(name string) error {
-	if c, cityInMap := m.cities[name]; cityInMap {
+	if c := m.lookup(name); c != nil {
 		if c.Exists {
-			c.Exists = false
+			c.destroy()
 		} else {
 			return fmt.Errorf("city %s have been already destroyed", name)
 		}
@@ -179,54 +514,238 @@ func (m *GameMap) destroyCity(name string) error {
 	return nil
 }
 
-// AssignAliens Assign aliens to cities. If alien number greater than available city, will return error
+// AssignAliens Assign aliens to cities. If alien number greater than available city, will return error.
+// Cities are picked by the placement strategy, uniformly random by default, see SetPlacementStrategy.
+// With stacked placement, cities are filled up to their capacity, see SetStackedPlacement.
 func (m *GameMap) AssignAliens(aliens []*Alien) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.assignAliens(aliens)
+}
+
+func (m *GameMap) assignAliens(aliens []*Alien) error {
+	pool := m.newLandingPool(m.cityIDs())
 	for _, alien := range aliens {
-		var candidates []*City
-		for _, city := range m.cities {
-			if city.Exists && city.AlienInCity == nil {
-				candidates = append(candidates, city)
-			}
-		}
-		if len(candidates) == 0 {
+		if pool.land(alien) == nil {
 			return fmt.Errorf("not enough exist cities available to assign aliens")
 		}
-		candidates[rand.Intn(len(candidates))].AlienInCity = alien
 	}
 	return nil
 }
 
+// AssignAliensAt places aliens at given cities by their index in aliens, like {0: "Akel", 1: "Delmon"}, and the others like AssignAliens.
+// Nothing is placed if an index is out of range, a city doesn't exist, or it can't hold all aliens placed in it.
+func (m *GameMap) AssignAliensAt(aliens []*Alien, at map[int]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	indexes, err := m.checkPlacements(len(aliens), at)
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if err = m.placeAlien(aliens[index], at[index]); err != nil {
+			return err
+		}
+	}
+	var others []*Alien
+	for i, alien := range aliens {
+		if _, placed := at[i]; !placed {
+			others = append(others, alien)
+		}
+	}
+	return m.assignAliens(others)
+}
+
+// checkPlacements validates placements of count aliens by their index, and returns indexes in order
+func (m *GameMap) checkPlacements(count int, at map[int]string) ([]int, error) {
+	indexes := make([]int, 0, len(at))
+	for index, name := range at {
+		if index < 0 || index >= count {
+			return nil, fmt.Errorf("no alien %d to place at %s, there are %d aliens", index, name, count)
+		}
+		if m.GetExistCity(name) == nil {
+			return nil, fmt.Errorf("city %s doesn't exist", name)
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	// Aliens placed so far in every city, an alien can't land in a city full or destroyed by aliens gathering before it
+	placed := make(map[*City]int)
+	for _, index := range indexes {
+		city := m.GetExistCity(at[index])
+		landed := len(city.Aliens) + placed[city]
+		if capacity := city.capacity(); capacity > 0 && landed >= capacity {
+			return nil, fmt.Errorf("city %s is full", city.Name)
+		}
+		if m.gatherThreshold > 0 && landed >= m.gatherThreshold {
+			return nil, fmt.Errorf("city %s is destroyed once %d aliens gather in it", city.Name, m.gatherThreshold)
+		}
+		placed[city]++
+	}
+	return indexes, nil
+}
+
+// PlaceAlien puts the alien into the named city, error if no such city, or it is destroyed or full
+func (m *GameMap) PlaceAlien(alien *Alien, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.placeAlien(alien, name)
+}
+
+func (m *GameMap) placeAlien(alien *Alien, name string) error {
+	city := m.GetExistCity(name)
+	if city == nil {
+		return fmt.Errorf("city %s doesn't exist", name)
+	}
+	if city.IsFull() {
+		return fmt.Errorf("city %s is full", name)
+	}
+	m.land(alien, city)
+	return nil
+}
+
+// canLand tells if an alien can be placed into the city. Cities of a compact map not touched yet are empty.
+func (m *GameMap) canLand(id int) bool {
+	city := m.cityList[id]
+	if city == nil {
+		return m.cityExists(id)
+	}
+	return city.Exists && (len(city.Aliens) == 0 || m.stackedPlacement && !city.IsFull())
+}
+
+// cityIDs lists IDs of all cities, in the order they are created
+func (m *GameMap) cityIDs() []int32 {
+	ids := make([]int32, len(m.cityList))
+	for i := range ids {
+		ids[i] = int32(i)
+	}
+	return ids
+}
+
+// land places the alien into the city
+func (m *GameMap) land(alien *Alien, city *City) {
+	city.join(alien)
+	city.gather()
+	if alien.Side == Defenders {
+		m.defended = true
+	}
+}
+
 // Update will be game updater. It will update game progress on city's basis.
+// Aliens on long roads walk first, so an alien arriving in a city moves on in the same tick.
+// Waves of the spawn schedule land, and cities are rebuilt, before anyone moves.
+// Game stops when an alien goes more than max steps (10000 by default), or max ticks are reached, or there is no invader left and no wave to come,
+// or defenders placed are all killed.
 func (m *GameMap) Update() (willContinue bool) {
-	for _, city := range m.cities {
-		if city.AlienInCity != nil {
-			_, steps := city.AlienInCity.Move(city)
-			if steps > 10000 {
-				return false
-			}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spawn()
+	m.tick++
+	m.rebuild()
+	m.failRoads()
+	if !m.updateTransits() {
+		return false
+	}
+	invaders, defenders := m.units()
+	// Every alien moves once, even if it walks into a city moving later
+	var turns []turn
+	for _, city := range m.occupiedCities() {
+		for _, alien := range city.Aliens {
+			turns = append(turns, turn{alien: alien, city: city})
 		}
 	}
-	return true
+	m.seedTurns(turns)
+	switch {
+	case m.stage != nil:
+		if !m.stage.round(m, turns) {
+			return false
+		}
+	case m.workers > 0:
+		if !m.moveInParallel(turns) {
+			return false
+		}
+	default:
+		if !m.playTurns(turns) {
+			return false
+		}
+	}
+	if m.maxTicks > 0 && m.tick >= m.maxTicks {
+		return false
+	}
+	return (invaders > 0 || m.wavesPending()) && (!m.defended || defenders > 0)
 }
 
 // DumpMap will dump the game map, the format exactly same as map file that input.
 func (m *GameMap) DumpMap() string {
 	var result []string
-	for _, city := range m.cities {
-		if city.Exists {
-			result = append(result, fmt.Sprintf("%s %s", city.Name, city.Neighborhoods.String()))
+	for id, city := range m.cityList {
+		if city == nil && m.cityExists(id) {
+			city = m.sketch(id)
+		}
+		if city != nil && city.Exists {
+			result = append(result, m.formatCity(city))
 		}
 	}
 	return strings.Join(result, "\n")
 }
 
-// ExistCityCount returns cities number that are not destroyed
-func (m *GameMap) ExistCityCount() int {
-	ret := 0
-	for _, c := range m.cities {
-		if c.Exists {
-			ret++
+// formatCity prints a city and its roads to cities still exist, as line(s) of the map file
+func (m *GameMap) formatCity(city *City) string {
+	if !m.graph {
+		exits := make([]string, DirectionSize)
+		for _, exit := range city.Exits() {
+			if exit.To.Exists {
+				exits[exit.Direction] = exit.String()
+			}
 		}
+		var roads []string
+		for _, direction := range m.Directions().Directions {
+			if exits[direction] != "" {
+				roads = append(roads, exits[direction])
+			}
+		}
+		return fmt.Sprintf("%s %s", city.declaration(), strings.Join(roads, " "))
 	}
-	return ret
+
+	var roads, oneWayRoads, lines []string
+	for _, exit := range city.Exits() {
+		if !exit.To.Exists {
+			continue
+		}
+		if exit.Road.OneWay {
+			oneWayRoads = append(oneWayRoads, exit.String())
+		} else {
+			roads = append(roads, exit.String())
+		}
+	}
+	if len(roads) > 0 {
+		lines = append(lines, fmt.Sprintf("%s -> %s", city.declaration(), strings.Join(roads, ", ")))
+	}
+	if len(oneWayRoads) > 0 {
+		lines = append(lines, fmt.Sprintf("%s => %s", city.declaration(), strings.Join(oneWayRoads, ", ")))
+	}
+	if len(lines) == 0 {
+		return city.declaration()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cities returns all cities in the order they are created, destroyed ones included
+func (m *GameMap) Cities() []*City {
+	if m.compact != nil {
+		for id := range m.cityList {
+			m.city(id)
+		}
+	}
+	return m.cityList
+}
+
+// Tick returns how many times the map has been updated
+func (m *GameMap) Tick() int {
+	return m.tick
+}
+
+// ExistCityCount returns cities number that are not destroyed
+func (m *GameMap) ExistCityCount() int {
+	return len(m.cityList) - len(m.ruinedCities())
 }
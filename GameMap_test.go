@@ -3,6 +3,7 @@ package alien_invastion
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"strings"
 	"testing"
 )
@@ -44,6 +45,20 @@ func TestDirectionFromString(t *testing.T) {
 			},
 			want: West,
 		},
+		{
+			name: "Good - NorthEast",
+			args: args{
+				from: "northeast",
+			},
+			want: NorthEast,
+		},
+		{
+			name: "Good - Up",
+			args: args{
+				from: "up",
+			},
+			want: Up,
+		},
 		{
 			name: "Good - With mixed case",
 			args: args{
@@ -99,6 +114,26 @@ func TestDirection_GetOpposite(t *testing.T) {
 			d:    West,
 			want: East,
 		},
+		{
+			name: "Good - NorthWest",
+			d:    NorthWest,
+			want: SouthEast,
+		},
+		{
+			name: "Good - SouthWest",
+			d:    SouthWest,
+			want: NorthEast,
+		},
+		{
+			name: "Good - Down",
+			d:    Down,
+			want: Up,
+		},
+		{
+			name: "Bad - Invalid",
+			d:    Invalid,
+			want: Invalid,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -107,6 +142,50 @@ func TestDirection_GetOpposite(t *testing.T) {
 	}
 }
 
+func TestNewDirectionSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		directions []Direction
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "Good - Closed under opposite",
+			directions: []Direction{North, South, Up, Down},
+			wantErr:    assert.NoError,
+		},
+		{
+			name:       "Bad - Missing opposite",
+			directions: []Direction{North, South, NorthEast},
+			wantErr:    assert.Error,
+		},
+		{
+			name:       "Bad - Invalid direction",
+			directions: []Direction{Invalid},
+			wantErr:    assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDirectionSet("custom", tt.directions...)
+			tt.wantErr(t, err, "NewDirectionSet(%v)", tt.directions)
+		})
+	}
+}
+
+func TestDirectionSet_Builtins(t *testing.T) {
+	for _, name := range []string{"compass", "8way", "compass3d", "8way3d"} {
+		t.Run(name, func(t *testing.T) {
+			set, err := DirectionSetFromString(name)
+			assert.NoError(t, err)
+			// Built-in sets should pass the same validation as custom ones
+			_, err = NewDirectionSet(set.Name, set.Directions...)
+			assert.NoError(t, err)
+		})
+	}
+	_, err := DirectionSetFromString("hexagonal")
+	assert.Error(t, err)
+}
+
 func TestGameMap_DestroyCity(t *testing.T) {
 	type fields struct {
 		cities map[string]*City
@@ -325,6 +404,39 @@ func TestGameMap_UpdateCityWithNeighborhood(t *testing.T) {
 				assert.Equal(t, "city1", gameMap.cities["city2"].Neighborhoods[East].Name)
 			},
 		},
+		{
+			name: "Bad - Direction not in map's set",
+			fields: fields{
+				cities: map[string]*City{},
+			},
+			args: args{
+				name:                 "city1",
+				direction:            NorthEast,
+				neighborhoodCityName: "city2",
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Nil(t, gameMap.cities["city2"])
+			},
+		},
+		{
+			name: "Bad - Graph map",
+			fields: fields{
+				cities: map[string]*City{},
+			},
+			patch: func(t *testing.T, gameMap *GameMap) {
+				gameMap.graph = true
+			},
+			args: args{
+				name:                 "city1",
+				direction:            North,
+				neighborhoodCityName: "city2",
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, 0, len(gameMap.cities))
+			},
+		},
 		{
 			name: "Bad - Update neighborhood conflict",
 			fields: fields{
@@ -363,6 +475,151 @@ func TestGameMap_UpdateCityWithNeighborhood(t *testing.T) {
 	}
 }
 
+func TestGameMap_UpdateCityWithOneWayRoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    func(t *testing.T, gameMap *GameMap)
+		wantErr  assert.ErrorAssertionFunc
+		validate func(*testing.T, *GameMap)
+	}{
+		{
+			name:    "Good - Neighborhood doesn't lead back",
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, "city2", gameMap.cities["city1"].Neighborhoods[East].Name)
+				assert.Nil(t, gameMap.cities["city2"].Neighborhoods[West])
+				assert.True(t, gameMap.cities["city2"].IsIsolatedOrDestroyed())
+			},
+		},
+		{
+			name: "Good - Neighborhood's opposite side leads elsewhere",
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.UpdateCityWithNeighborhood("city2", West, "city3")
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, "city3", gameMap.cities["city2"].Neighborhoods[West].Name)
+			},
+		},
+		{
+			name: "Good - Reverse one-way becomes two-way",
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.UpdateCityWithOneWayRoad("city2", West, "city1")
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.False(t, gameMap.cities["city1"].Roads[0].OneWay)
+				assert.False(t, gameMap.cities["city2"].IsIsolatedOrDestroyed())
+			},
+		},
+		{
+			name: "Bad - City's side leads elsewhere",
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.UpdateCityWithNeighborhood("city1", East, "city3")
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, "city3", gameMap.cities["city1"].Neighborhoods[East].Name)
+			},
+		},
+		{
+			name: "Bad - Already two-way",
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.UpdateCityWithNeighborhood("city2", West, "city1")
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.False(t, gameMap.cities["city1"].Roads[0].OneWay)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGameMap()
+			if tt.patch != nil {
+				tt.patch(t, m)
+			}
+			tt.wantErr(t, m.UpdateCityWithOneWayRoad("city1", East, "city2"), "UpdateCityWithOneWayRoad(city1, east, city2)")
+			tt.validate(t, m)
+		})
+	}
+	// Two-way declaration against an existing one-way road
+	m := NewGameMap()
+	_ = m.UpdateCityWithOneWayRoad("city1", East, "city2")
+	assert.Error(t, m.UpdateCityWithNeighborhood("city2", West, "city1"))
+}
+
+func TestGameMap_SetRoadLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      string
+		direction Direction
+		to        string
+		length    int
+		wantErr   assert.ErrorAssertionFunc
+		want      int
+	}{
+		{
+			name:      "Good - From declaring side",
+			from:      "city1",
+			direction: North,
+			to:        "city2",
+			length:    3,
+			wantErr:   assert.NoError,
+			want:      3,
+		},
+		{
+			name:      "Good - From other side",
+			from:      "city2",
+			direction: South,
+			to:        "city1",
+			length:    3,
+			wantErr:   assert.NoError,
+			want:      3,
+		},
+		{
+			name:      "Bad - Wrong direction",
+			from:      "city1",
+			direction: East,
+			to:        "city2",
+			length:    3,
+			wantErr:   assert.Error,
+			want:      0,
+		},
+		{
+			name:      "Bad - Unknown city",
+			from:      "city1",
+			direction: North,
+			to:        "city3",
+			length:    3,
+			wantErr:   assert.Error,
+			want:      0,
+		},
+		{
+			name:      "Bad - Zero length",
+			from:      "city1",
+			direction: North,
+			to:        "city2",
+			length:    0,
+			wantErr:   assert.Error,
+			want:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGameMap()
+			_ = m.UpdateCityWithNeighborhood("city1", North, "city2")
+			tt.wantErr(t, m.SetRoadLength(tt.from, tt.direction, tt.to, tt.length))
+			assert.Equal(t, tt.want, m.cities["city1"].Roads[0].Length)
+		})
+	}
+	// Conflict between both sides
+	m := NewGameMap()
+	_ = m.UpdateCityWithNeighborhood("city1", North, "city2")
+	assert.NoError(t, m.SetRoadLength("city1", North, "city2", 3))
+	assert.Error(t, m.SetRoadLength("city2", South, "city1", 4))
+}
+
 func TestGameMap_UpsertCity(t *testing.T) {
 	type fields struct {
 		cities map[string]*City
@@ -466,6 +723,7 @@ func TestNewCity(t *testing.T) {
 			city := newCity(tt.args.name)
 			assert.NotNil(t, city.Neighborhoods)
 			assert.True(t, city.Exists)
+			assert.Equal(t, 1, city.HitPoints)
 		})
 	}
 }
@@ -599,12 +857,13 @@ func TestCity_AlienMigrate(t *testing.T) {
 		Name          string
 		Neighborhoods Neighborhoods
 		Exists        bool
-		AlienInCity   *Alien
+		Aliens        []*Alien
 	}
 	type args struct {
 		to    *City
 		patch func(t *testing.T, from *City, alien *Alien)
 	}
+	ids := &AlienIDs{}
 	tests := []struct {
 		name     string
 		fields   fields
@@ -614,9 +873,9 @@ func TestCity_AlienMigrate(t *testing.T) {
 		{
 			name: "Migrate to empty city",
 			fields: fields{
-				Name:        "city1",
-				Exists:      true,
-				AlienInCity: NewAlien(),
+				Name:   "city1",
+				Exists: true,
+				Aliens: []*Alien{ids.NewAlien()},
 			},
 			args: args{
 				to: &City{
@@ -625,8 +884,8 @@ func TestCity_AlienMigrate(t *testing.T) {
 				},
 			},
 			validate: func(t *testing.T, from, to *City) {
-				assert.Nil(t, from.AlienInCity)
-				assert.NotNil(t, from.AlienInCity)
+				assert.Empty(t, from.Aliens)
+				assert.NotEmpty(t, from.Aliens)
 				assert.True(t, from.Exists)
 				assert.True(t, to.Exists)
 			},
@@ -634,20 +893,20 @@ func TestCity_AlienMigrate(t *testing.T) {
 		{
 			name: "Migrate to occupied city",
 			fields: fields{
-				Name:        "city1",
-				Exists:      true,
-				AlienInCity: NewAlien(),
+				Name:   "city1",
+				Exists: true,
+				Aliens: []*Alien{ids.NewAlien()},
 			},
 			args: args{
 				to: &City{
-					Name:        "city2",
-					Exists:      true,
-					AlienInCity: NewAlien(),
+					Name:   "city2",
+					Exists: true,
+					Aliens: []*Alien{ids.NewAlien()},
 				},
 			},
 			validate: func(t *testing.T, from, to *City) {
-				assert.Nil(t, from.AlienInCity)
-				assert.NotNil(t, from.AlienInCity)
+				assert.Empty(t, from.Aliens)
+				assert.NotEmpty(t, from.Aliens)
 				assert.True(t, from.Exists)
 				//Target city should have been destroyed
 				assert.False(t, to.Exists)
@@ -658,11 +917,11 @@ func TestCity_AlienMigrate(t *testing.T) {
 			fields: fields{
 				Name:   "city1",
 				Exists: true,
-				AlienInCity: &Alien{
+				Aliens: []*Alien{{
 					Number: 0,
 					Steps:  0,
 					Alive:  true,
-				},
+				}},
 			},
 			args: args{
 				to: &City{
@@ -672,7 +931,7 @@ func TestCity_AlienMigrate(t *testing.T) {
 			},
 			validate: func(t *testing.T, from, to *City) {
 				//Alien should stay in current city
-				assert.NotNil(t, to.AlienInCity)
+				assert.NotEmpty(t, to.Aliens)
 				assert.True(t, from.Exists)
 				assert.False(t, to.Exists)
 			},
@@ -684,12 +943,87 @@ func TestCity_AlienMigrate(t *testing.T) {
 				Name:          tt.fields.Name,
 				Neighborhoods: tt.fields.Neighborhoods,
 				Exists:        tt.fields.Exists,
-				AlienInCity:   tt.fields.AlienInCity,
+				Aliens:        tt.fields.Aliens,
 			}
 			//connect from and to
 			c.Neighborhoods = Neighborhoods{tt.args.to, nil, nil, nil}
 			tt.args.to.Neighborhoods = Neighborhoods{nil, nil, c, nil}
-			c.AlienMigrate(tt.args.to)
+			c.AlienMigrate(tt.fields.Aliens[0], tt.args.to)
+		})
+	}
+}
+
+func TestCity_fight(t *testing.T) {
+	tests := []struct {
+		name          string
+		hitPoints     int
+		defense       int
+		fights        int
+		wantExists    bool
+		wantHitPoints int
+		wantEvent     EventType
+	}{
+		{
+			name:          "Unfortified city is destroyed at once",
+			hitPoints:     1,
+			fights:        1,
+			wantExists:    false,
+			wantHitPoints: -1,
+			wantEvent:     CityDestroyed,
+		},
+		{
+			name:          "Fortified city survives",
+			hitPoints:     5,
+			fights:        2,
+			wantExists:    true,
+			wantHitPoints: 1,
+			wantEvent:     CityDamaged,
+		},
+		{
+			name:          "Fortified city falls after several fights",
+			hitPoints:     5,
+			fights:        3,
+			wantExists:    false,
+			wantHitPoints: -1,
+			wantEvent:     CityDestroyed,
+		},
+		{
+			name:          "Defense absorbs damage",
+			hitPoints:     2,
+			defense:       1,
+			fights:        1,
+			wantExists:    true,
+			wantHitPoints: 1,
+			wantEvent:     CityDamaged,
+		},
+		{
+			name:          "Defense never heals",
+			hitPoints:     2,
+			defense:       3,
+			fights:        3,
+			wantExists:    true,
+			wantHitPoints: 2,
+			wantEvent:     CityDamaged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewGameMap()
+			c := m.UpsertCity("city1")
+			c.HitPoints = tt.hitPoints
+			c.Defense = tt.defense
+			for i := 0; i < tt.fights; i++ {
+				attacker, defender := m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien()
+				c.Aliens = []*Alien{defender}
+				c.alienArrive(attacker)
+				assert.False(t, attacker.Alive)
+				assert.False(t, defender.Alive)
+				assert.Empty(t, c.Aliens)
+			}
+			assert.Equal(t, tt.wantExists, c.Exists)
+			assert.Equal(t, tt.wantHitPoints, c.HitPoints)
+			assert.Equal(t, tt.fights, len(m.Events()))
+			assert.Equal(t, tt.wantEvent, m.Events()[tt.fights-1].Type)
 		})
 	}
 }
@@ -713,6 +1047,81 @@ func TestGameMap_DumpMap(t *testing.T) {
 				assert.Equal(t, 4, strings.Count(dumpedString, "\n"))
 			},
 		},
+		{
+			name: "Follow direction set order",
+			gameMap: func() *GameMap {
+				m := NewGameMapWithDirections(EightWayVerticalDirections)
+				_ = m.UpdateCityWithNeighborhood("Foo", Up, "Attic")
+				_ = m.UpdateCityWithNeighborhood("Foo", NorthEast, "Bar")
+				_ = m.UpdateCityWithNeighborhood("Foo", North, "Baz")
+				return m
+			}(),
+			validate: func(t *testing.T, dumpedString string) {
+				assert.Contains(t, dumpedString, "Foo north=Baz northeast=Bar up=Attic")
+				assert.Contains(t, dumpedString, "Attic down=Foo")
+				assert.Contains(t, dumpedString, "Bar southwest=Foo")
+			},
+		},
+		{
+			name: "Graph map",
+			gameMap: func() *GameMap {
+				m, _ := (&StreamParser{Graph: true}).ParseFile("test_resources/graph_map.txt")
+				m.GetExistCity("Beth").Exists = false
+				return m
+			}(),
+			validate: func(t *testing.T, dumpedString string) {
+				assert.Contains(t, strings.Split(dumpedString, "\n"), "Akel -> Delmon, Summerjack, highway=Ur-Gorath")
+				assert.Contains(t, dumpedString, "Springfield -> bridge=Delmon")
+				assert.Contains(t, dumpedString, "Vampfont")
+				assert.NotContains(t, dumpedString, "Beth")
+			},
+		},
+		{
+			name: "One-way roads",
+			gameMap: func() *GameMap {
+				m, _ := parser.ParseString("Foo north=>Bar west=Baz\nBar east=>Bee")
+				return m
+			}(),
+			validate: func(t *testing.T, dumpedString string) {
+				assert.Contains(t, strings.Split(dumpedString, "\n"), "Foo north=>Bar west=Baz")
+				assert.Contains(t, dumpedString, "Bar east=>Bee")
+				assert.Contains(t, strings.Split(dumpedString, "\n"), "Bee ")
+			},
+		},
+		{
+			name: "One-way roads in graph map",
+			gameMap: func() *GameMap {
+				m, _ := (&StreamParser{Graph: true}).ParseString("Foo -> Bar\nFoo => Baz, river=Bee\nBee => Foo")
+				return m
+			}(),
+			validate: func(t *testing.T, dumpedString string) {
+				assert.Contains(t, dumpedString, "Foo -> Bar, river=Bee\nFoo => Baz")
+				assert.Contains(t, dumpedString, "Bee -> river=Foo")
+				assert.Contains(t, strings.Split(dumpedString, "\n"), "Baz")
+			},
+		},
+		{
+			name: "Road lengths",
+			gameMap: func() *GameMap {
+				m, _ := parser.ParseString("Foo north=Bar:3 west=>Baz:2 east=Bee:1")
+				return m
+			}(),
+			validate: func(t *testing.T, dumpedString string) {
+				assert.Contains(t, strings.Split(dumpedString, "\n"), "Foo north=Bar:3 west=>Baz:2 east=Bee")
+				assert.Contains(t, dumpedString, "Bar south=Foo:3")
+			},
+		},
+		{
+			name: "City attributes",
+			gameMap: func() *GameMap {
+				m, _ := parser.ParseString("Foo hp=5 defense=1 north=Bar\nBar hp=1")
+				return m
+			}(),
+			validate: func(t *testing.T, dumpedString string) {
+				assert.Contains(t, strings.Split(dumpedString, "\n"), "Foo hp=5 defense=1 north=Bar")
+				assert.Contains(t, strings.Split(dumpedString, "\n"), "Bar south=Foo")
+			},
+		},
 		{
 			name: "Don't print destroyed cities",
 			gameMap: func() *GameMap {
@@ -809,15 +1218,245 @@ func TestGameMap_AssignAliens(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Cities 5 with capacity 2 and Aliens 10 stacked",
+			args: args{
+				gameMap: func() *GameMap {
+					m, _ := parser.ParseFile("test_resources/standard_input1.txt")
+					m.SetCityCapacity(2)
+					m.SetStackedPlacement(true)
+					return m
+				}(),
+				alienCount: 10,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Cities 5 with capacity 2 and Aliens 11 stacked",
+			args: args{
+				gameMap: func() *GameMap {
+					m, _ := parser.ParseFile("test_resources/standard_input1.txt")
+					m.SetCityCapacity(2)
+					m.SetStackedPlacement(true)
+					return m
+				}(),
+				alienCount: 11,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Cities 5 with capacity 2 and Aliens 6 not stacked",
+			args: args{
+				gameMap: func() *GameMap {
+					m, _ := parser.ParseFile("test_resources/standard_input1.txt")
+					m.SetCityCapacity(2)
+					return m
+				}(),
+				alienCount: 6,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Cities 5 unlimited and Aliens 100 stacked",
+			args: args{
+				gameMap: func() *GameMap {
+					m, _ := parser.ParseFile("test_resources/standard_input1.txt")
+					m.SetCityCapacity(0)
+					m.SetStackedPlacement(true)
+					return m
+				}(),
+				alienCount: 100,
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var aliens []*Alien
 			for i := 0; i < tt.args.alienCount; i++ {
-				aliens = append(aliens, NewAlien())
+				aliens = append(aliens, tt.args.gameMap.AlienIDs().NewAlien())
 			}
 
 			assert.Equal(t, tt.wantErr, nil != tt.args.gameMap.AssignAliens(aliens))
 		})
 	}
 }
+
+func TestGameMap_UpdateMovesOncePerTick(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar")
+	alien := &Alien{Number: 0, Alive: true}
+	m.cities["Foo"].Aliens = []*Alien{alien}
+	assert.True(t, m.Update())
+	assert.Equal(t, []*Alien{alien}, m.cities["Bar"].Aliens)
+	assert.Equal(t, 1, alien.Steps)
+	assert.True(t, m.Update())
+	assert.Equal(t, []*Alien{alien}, m.cities["Foo"].Aliens)
+	assert.Equal(t, 2, alien.Steps)
+}
+
+func TestGameMap_UpdateLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxSteps  int
+		maxTicks  int
+		wantTicks int
+	}{
+		{name: "Max steps", maxSteps: 5, wantTicks: 6},
+		{name: "Max ticks", maxSteps: 10000, maxTicks: 3, wantTicks: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("Foo east=Bar")
+			m.SetMaxSteps(tt.maxSteps)
+			m.SetMaxTicks(tt.maxTicks)
+			m.cities["Foo"].Aliens = []*Alien{{Number: 0, Alive: true}}
+			for m.Update() {
+			}
+			assert.Equal(t, tt.wantTicks, m.Tick())
+		})
+	}
+}
+
+func TestGameMap_PlaceAlien(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar capacity=2\nBaz")
+	_ = m.destroyCity("Baz")
+	alien := m.AlienIDs().NewAlien()
+	assert.NoError(t, m.PlaceAlien(alien, "Foo"))
+	assert.Equal(t, []*Alien{alien}, m.cities["Foo"].Aliens)
+	assert.NoError(t, m.PlaceAlien(m.AlienIDs().NewAlien(), "Foo"))
+	assert.Error(t, m.PlaceAlien(m.AlienIDs().NewAlien(), "Foo"))
+	assert.Error(t, m.PlaceAlien(m.AlienIDs().NewAlien(), "Baz"))
+	assert.Error(t, m.PlaceAlien(m.AlienIDs().NewAlien(), "Atlantis"))
+}
+
+func TestGameMap_AssignAliensAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		at      map[int]string
+		wantErr assert.ErrorAssertionFunc
+		want    map[int]string
+	}{
+		{
+			name:    "Aliens at given cities",
+			at:      map[int]string{0: "Akel", 2: "Delmon"},
+			wantErr: assert.NoError,
+			want:    map[int]string{0: "Akel", 2: "Delmon"},
+		},
+		{
+			name:    "No placement",
+			at:      nil,
+			wantErr: assert.NoError,
+			want:    map[int]string{},
+		},
+		{
+			name:    "No such alien",
+			at:      map[int]string{0: "Akel", 3: "Delmon"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "No such city",
+			at:      map[int]string{0: "Akel", 1: "Atlantis"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "City taken",
+			at:      map[int]string{0: "Akel", 1: "Akel"},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+			aliens := []*Alien{m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien()}
+			err := m.AssignAliensAt(aliens, tt.at)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			for i, alien := range aliens {
+				var found *City
+				for _, city := range m.Cities() {
+					if len(city.Aliens) > 0 && city.Aliens[0] == alien {
+						found = city
+					}
+				}
+				if assert.NotNil(t, found) && tt.want[i] != "" {
+					assert.Equal(t, tt.want[i], found.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestGameMap_AssignAliensAtValidatesFirst(t *testing.T) {
+	tests := []struct {
+		name   string
+		gather int
+		at     map[int]string
+	}{
+		{name: "City doesn't exist", at: map[int]string{0: "Akel", 1: "Atlantis"}},
+		{name: "City full", at: map[int]string{0: "Akel", 1: "Akel"}},
+		{name: "City destroyed by gathering", gather: 1, at: map[int]string{0: "Akel", 1: "Akel"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+			if tt.gather > 0 {
+				m.SetCityCapacity(0)
+				m.SetGatherThreshold(tt.gather)
+			}
+			assert.Error(t, m.AssignAliensAt([]*Alien{m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien()}, tt.at))
+			assert.True(t, m.cities["Akel"].Exists)
+			assert.Empty(t, m.cities["Akel"].Aliens)
+		})
+	}
+}
+
+func BenchmarkGameMap_AssignAliens(b *testing.B) {
+	for _, grid := range gridBuilders {
+		for _, size := range []struct{ width, aliens int }{{100, 1000}, {300, 10000}, {1000, 100000}} {
+			m, _ := grid.build(size.width, size.width)
+			b.Run(fmt.Sprintf("%s/%d aliens in %d cities", grid.name, size.aliens, size.width*size.width), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					for _, city := range m.cityList {
+						if city != nil {
+							city.Aliens = nil
+						}
+					}
+					aliens := make([]*Alien, size.aliens)
+					for j := range aliens {
+						aliens[j] = m.AlienIDs().NewAlien()
+					}
+					b.StartTimer()
+					if err := m.AssignAliens(aliens); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGameMap_Update(b *testing.B) {
+	for _, grid := range gridBuilders {
+		for _, size := range []struct{ width, aliens int }{{100, 10}, {1000, 100}, {1000, 10000}} {
+			m, _ := grid.build(size.width, size.width)
+			m.SetSeed(0)
+			m.SetMaxSteps(math.MaxInt)
+			// Aliens of one faction never fight, so every tick moves all of them
+			m.SetCityCapacity(0)
+			aliens := make([]*Alien, size.aliens)
+			for i := range aliens {
+				aliens[i] = m.AlienIDs().NewAlien()
+				aliens[i].Faction = "red"
+			}
+			_ = m.AssignAliens(aliens)
+			b.Run(fmt.Sprintf("%s/%d aliens in %d cities", grid.name, size.aliens, size.width*size.width), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					m.Update()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScenario_NewGame(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    func(s *Scenario)
+		wantErr  assert.ErrorAssertionFunc
+		validate func(t *testing.T, g *Game)
+	}{
+		{
+			name:    "Classic game",
+			patch:   func(s *Scenario) {},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, g *Game) {
+				assert.Equal(t, 5, len(g.Aliens))
+				assert.Empty(t, g.Defenders)
+			},
+		},
+		{
+			name: "Placements and defenders",
+			patch: func(s *Scenario) {
+				s.Aliens.Placements = []Placement{{City: "Akel", Count: 1}, {City: "Delmon", Count: 1}}
+				s.Aliens.Factions = []string{"red", "blue"}
+				s.Defenders.Count = 2
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, g *Game) {
+				assert.Equal(t, []*Alien{g.Aliens[0]}, g.Map.cities["Akel"].Aliens)
+				assert.Equal(t, []*Alien{g.Aliens[1]}, g.Map.cities["Delmon"].Aliens)
+				assert.Equal(t, "red", g.Aliens[0].Faction)
+				assert.Equal(t, "blue", g.Aliens[1].Faction)
+				assert.Equal(t, 2, len(g.Defenders))
+				assert.Equal(t, Guard{}, g.Defenders[0].Strategy)
+			},
+		},
+		{
+			name: "Aliens at given cities and placement strategy",
+			patch: func(s *Scenario) {
+				s.Aliens.Placements = []Placement{{City: "Akel", Count: 1}}
+				s.Aliens.At = map[int]string{1: "Delmon", 4: "Rickel"}
+				s.Aliens.Placement = "spread"
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, g *Game) {
+				assert.Equal(t, []*Alien{g.Aliens[0]}, g.Map.cities["Akel"].Aliens)
+				assert.Equal(t, []*Alien{g.Aliens[1]}, g.Map.cities["Delmon"].Aliens)
+				assert.Equal(t, []*Alien{g.Aliens[4]}, g.Map.cities["Rickel"].Aliens)
+				assert.Equal(t, SpreadPlacement{}, g.Map.placement)
+			},
+		},
+		{
+			name:    "Workers",
+			patch:   func(s *Scenario) { s.Workers = 4 },
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, g *Game) {
+				assert.Equal(t, 4, g.Map.workers)
+			},
+		},
+		{
+			name:    "Alien names",
+			patch:   func(s *Scenario) { s.Aliens.Names = []string{"Zorg"} },
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, g *Game) {
+				assert.Equal(t, 0, g.Aliens[0].Number)
+				assert.Equal(t, "Zorg", g.Map.AlienIDs().Name(0))
+				assert.Equal(t, "1", g.Map.AlienIDs().Name(1))
+			},
+		},
+		{
+			name:    "Workers and actors",
+			patch:   func(s *Scenario) { s.Workers, s.Actors = 4, true },
+			wantErr: assert.Error,
+		},
+		{
+			name:    "Negative strength",
+			patch:   func(s *Scenario) { s.Aliens.Strength = -1 },
+			wantErr: assert.Error,
+		},
+		{
+			name:    "No health",
+			patch:   func(s *Scenario) { s.Aliens.Health = 0 },
+			wantErr: assert.Error,
+		},
+		{
+			name: "Alien placed twice",
+			patch: func(s *Scenario) {
+				s.Aliens.Placements = []Placement{{City: "Akel", Count: 1}}
+				s.Aliens.At = map[int]string{0: "Delmon"}
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Unknown city to place an alien at",
+			patch: func(s *Scenario) {
+				s.Aliens.At = map[int]string{0: "Atlantis"}
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Unknown placement strategy",
+			patch: func(s *Scenario) {
+				s.Aliens.Placement = "everywhere"
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Limits",
+			patch: func(s *Scenario) {
+				s.Termination = Termination{MaxSteps: 10, MaxTicks: 2}
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, g *Game) {
+				g.Run()
+				assert.LessOrEqual(t, g.Map.Tick(), 2)
+			},
+		},
+		{
+			name: "No step limit",
+			patch: func(s *Scenario) {
+				s.Aliens.Strategy = "guard"
+				s.Termination = Termination{MaxSteps: 0, MaxTicks: 30}
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, g *Game) {
+				g.Run()
+				assert.Equal(t, 30, g.Map.Tick())
+			},
+		},
+		{
+			name: "Negative limit",
+			patch: func(s *Scenario) {
+				s.Termination = Termination{MaxSteps: -1}
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Too many placements",
+			patch: func(s *Scenario) {
+				s.Aliens.Placements = []Placement{{City: "Akel", Count: 6}}
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Unknown city",
+			patch: func(s *Scenario) {
+				s.Aliens.Placements = []Placement{{City: "Atlantis", Count: 1}}
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Unknown strategy",
+			patch: func(s *Scenario) {
+				s.Aliens.Strategy = "teleport"
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Unknown combat",
+			patch: func(s *Scenario) {
+				s.Rules.Combat = "chess"
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "Broken wave",
+			patch: func(s *Scenario) {
+				s.Spawn = []string{"soon"}
+			},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScenario()
+			s.Map = "test_resources/sample_map.txt"
+			s.Aliens.Count = 5
+			tt.patch(s)
+			g, err := s.NewGame()
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			tt.validate(t, g)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package alien_invastion
+
+import "fmt"
+
+// NewGridGameMap generates a compass map of width x height cities, like a city block.
+// City at column x and row y is named GridCityName(x, y), its north is row y-1 and its east is column x+1.
+func NewGridGameMap(width, height int) (*GameMap, error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("invalid grid size %dx%d", width, height)
+	}
+	m := NewGameMap()
+	m.cityList = make([]*City, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			name := GridCityName(x, y)
+			m.UpsertCity(name)
+			if x > 0 {
+				if err := m.UpdateCityWithNeighborhood(name, West, GridCityName(x-1, y)); err != nil {
+					return nil, err
+				}
+			}
+			if y > 0 {
+				if err := m.UpdateCityWithNeighborhood(name, North, GridCityName(x, y-1)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// NewCompactGridGameMap generates the same map as NewGridGameMap, as a compact map, see GameMap.Compact.
+// It is meant for maps with millions of cities.
+func NewCompactGridGameMap(width, height int) (*GameMap, error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("invalid grid size %dx%d", width, height)
+	}
+	names := make([]string, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			names = append(names, GridCityName(x, y))
+		}
+	}
+	// Roads are added in the same order as NewGridGameMap connects them
+	compact := newCompactMap(names, (width-1)*height+width*(height-1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			id := int32(y*width + x)
+			if x > 0 {
+				compact.addRoad(id, id-1, West)
+			}
+			if y > 0 {
+				compact.addRoad(id, id-int32(width), North)
+			}
+		}
+	}
+	compact.link()
+	m := NewGameMap()
+	m.compact = compact
+	m.cityList = make([]*City, len(names))
+	return m, nil
+}
+
+// GridCityName names the city at column x and row y of a grid map
+func GridCityName(x, y int) string {
+	return fmt.Sprintf("%d-%d", x, y)
+}
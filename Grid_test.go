@@ -0,0 +1,104 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"testing"
+)
+
+func TestNewGridGameMap(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantErr       assert.ErrorAssertionFunc
+		wantLines     []string
+	}{
+		{
+			name:    "2x2 grid",
+			width:   2,
+			height:  2,
+			wantErr: assert.NoError,
+			wantLines: []string{
+				"0-0 south=0-1 east=1-0",
+				"1-0 west=0-0 south=1-1",
+				"0-1 north=0-0 east=1-1",
+				"1-1 north=1-0 west=0-1",
+			},
+		},
+		{
+			name:      "Single city",
+			width:     1,
+			height:    1,
+			wantErr:   assert.NoError,
+			wantLines: []string{"0-0"},
+		},
+		{
+			name:    "Empty grid",
+			width:   0,
+			height:  3,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewGridGameMap(tt.width, tt.height)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tt.width*tt.height, m.ExistCityCount())
+			dumped := m.DumpMap()
+			for _, line := range tt.wantLines {
+				assert.Contains(t, dumped, line)
+			}
+		})
+	}
+}
+
+// gridBuilders generate the same grid in both storages
+var gridBuilders = []struct {
+	name  string
+	build func(width, height int) (*GameMap, error)
+}{
+	{name: "regular", build: NewGridGameMap},
+	{name: "compact", build: NewCompactGridGameMap},
+}
+
+func TestNewCompactGridGameMap(t *testing.T) {
+	m, _ := NewGridGameMap(7, 5)
+	compact, err := NewCompactGridGameMap(7, 5)
+	assert.NoError(t, err)
+	assert.True(t, compact.IsCompact())
+	assert.Equal(t, m.DumpMap(), compact.DumpMap())
+	_, err = NewCompactGridGameMap(3, 0)
+	assert.Error(t, err)
+}
+
+func BenchmarkNewGridGameMap(b *testing.B) {
+	for _, grid := range gridBuilders {
+		for _, width := range []int{100, 1000} {
+			b.Run(fmt.Sprintf("%s/%d cities", grid.name, width*width), func(b *testing.B) {
+				b.ReportAllocs()
+				var m *GameMap
+				for i := 0; i < b.N; i++ {
+					m, _ = grid.build(width, width)
+				}
+				m = nil
+				b.ReportMetric(float64(heapBytes(func() { m, _ = grid.build(width, width) }))/float64(width*width), "heap-B/city")
+				runtime.KeepAlive(m)
+			})
+		}
+	}
+}
+
+// heapBytes measures heap kept alive by build
+func heapBytes(build func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	return after.HeapAlloc - before.HeapAlloc
+}
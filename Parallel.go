@@ -0,0 +1,264 @@
+package alien_invastion
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// SetWorkers moves units on given number of goroutines in every tick, 0 (default) for the classic engine.
+// With workers, cities holding units are split in every tick into groups that can't reach each other within the tick,
+// and each group moves on its own. Every turn makes its random decisions with dice of its own, see dice, and events and
+// aliens on roads are put back in the order the classic engine produces them, so a game plays exactly like the classic
+// engine with the same seed, whatever the number of workers. Turns from the first unit that may go more than max steps
+// on are played by the classic engine, so the game stops at the same turn. Ticks with a unit moved by a Player are
+// played by the classic engine as well, on the goroutine calling Update, as players may read the whole map.
+func (m *GameMap) SetWorkers(workers int) {
+	m.workers = workers
+}
+
+// shard is a group of cities moving on its own in a tick. Whatever its units change outside of their cities is kept
+// in the shard, and applied to the map once all groups have moved.
+type shard struct {
+	dice     *dice
+	turns    []int // Turns of units of the group, in order of the tick
+	turn     int   // Turn being played
+	events   []Event
+	eventsAt []int // Turn of every event
+	departed []*transit
+	leftAt   []int // Turn of every transit
+	enrolled []*City
+	ruined   []*City
+	parent   *shard // Group the shard has been merged into while splitting turns, nil for a group
+}
+
+// root returns the group the shard has been merged into
+func (s *shard) root() *shard {
+	for s.parent != nil {
+		if s.parent.parent != nil {
+			s.parent = s.parent.parent
+		}
+		s = s.parent
+	}
+	return s
+}
+
+func (s *shard) emit(e Event) {
+	s.events = append(s.events, e)
+	s.eventsAt = append(s.eventsAt, s.turn)
+}
+
+// splitmix is a tiny random source, cheap to seed for every turn
+type splitmix uint64
+
+func (s *splitmix) Seed(seed int64) {
+	*s = splitmix(seed)
+}
+
+func (s *splitmix) Uint64() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *splitmix) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// turn is a unit moving from a city in a tick
+type turn struct {
+	alien *Alien
+	city  *City
+	seed  int64 // Seed of the dice of the turn, drawn from the RNG of the simulation in order of the tick
+}
+
+// dice make random decisions of a turn. Every turn of a tick draws the seed of its dice from the RNG of the simulation
+// in order of the tick, whether its unit is still alive or not, so a turn makes the same decisions on any goroutine.
+type dice struct {
+	source splitmix
+	rng    *rand.Rand
+}
+
+func newDice() *dice {
+	d := &dice{}
+	d.rng = rand.New(&d.source)
+	return d
+}
+
+// seedTurns draws seeds of dice of the turns, in order
+func (m *GameMap) seedTurns(turns []turn) {
+	for i := range turns {
+		turns[i].seed = m.Rand().Int63()
+	}
+}
+
+// play moves the unit of the turn rolling given dice, false if the unit went more than max steps
+func (m *GameMap) play(t turn, d *dice) bool {
+	if !t.alien.Alive {
+		return true
+	}
+	d.source.Seed(t.seed)
+	_, steps := t.alien.Move(t.city)
+	return steps <= m.maxSteps
+}
+
+// playTurns is the classic engine, moving units of the turns one after another.
+// willContinue is false once a unit goes more than max steps, units after it don't move.
+func (m *GameMap) playTurns(turns []turn) (willContinue bool) {
+	m.dice = newDice()
+	defer func() {
+		m.dice = nil
+	}()
+	for _, t := range turns {
+		if !m.play(t, m.dice) {
+			return false
+		}
+	}
+	return true
+}
+
+// reaches calls visit with the city of a turn, and the cities its exits lead to, the cities a move from it may touch.
+// Cities of a compact map are wired first, so moves don't create cities or roads.
+func reaches(from *City, visit func(*City)) {
+	from.wire()
+	visit(from)
+	// Same roads as City.Exits, without listing them
+	for _, road := range from.Roads {
+		if !road.Destroyed && (!road.OneWay || road.From == from) {
+			to := road.Other(from)
+			to.wire()
+			visit(to)
+		}
+	}
+}
+
+// firstExceeding returns the first of the turns whose unit may go more than max steps, the number of turns if none.
+// Steps of a unit only change in its own turn, a unit goes more than max steps in its turn unless it is killed before.
+func (m *GameMap) firstExceeding(turns []turn) int {
+	for i, t := range turns {
+		if t.alien.Alive && t.alien.Steps+1 > m.maxSteps {
+			return i
+		}
+	}
+	return len(turns)
+}
+
+// shards splits turns into groups of cities which can't reach each other within a tick : a unit reaches its city
+// and the cities its exits lead to, and two groups never reach the same city. Every city reached is bound to its group,
+// and returned to be unbound once the tick is over.
+func (m *GameMap) shards(turns []turn) (shards []*shard, reached []*City) {
+	// Union find over turns, a turn starts a group of its own, and joins groups of turns reaching the same cities
+	nodes := make([]shard, len(turns))
+	reach := func(city *City, s *shard) {
+		if city.shard == nil {
+			city.shard = s
+			reached = append(reached, city)
+		} else if root, other := s.root(), city.shard.root(); root != other {
+			other.parent = root
+		}
+	}
+	for i, t := range turns {
+		reaches(t.city, func(city *City) {
+			reach(city, &nodes[i])
+		})
+	}
+
+	// Groups are ordered by their first turn, and seeded in this order
+	for i := range turns {
+		root := nodes[i].root()
+		if root.dice == nil {
+			root.dice = newDice()
+			shards = append(shards, root)
+		}
+		root.turns = append(root.turns, i)
+	}
+	for _, city := range reached {
+		city.shard = city.shard.root()
+	}
+	return shards, reached
+}
+
+// played tells if a unit of the turns is moved by a Player, or a pointer to one
+func played(turns []turn) bool {
+	for _, t := range turns {
+		switch t.alien.Strategy.(type) {
+		case Player, *Player:
+			if t.alien.Alive {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// moveInParallel moves units of the turns in groups on the workers, and applies what they changed in order of the turns.
+// willContinue is false if a unit went more than max steps.
+func (m *GameMap) moveInParallel(turns []turn) (willContinue bool) {
+	if played(turns) {
+		return m.playTurns(turns)
+	}
+	cut := m.firstExceeding(turns)
+	shards, reached := m.shards(turns[:cut])
+	var wg sync.WaitGroup
+	next := make(chan *shard)
+	for i := 0; i < m.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range next {
+				for _, i := range s.turns {
+					s.turn = i
+					m.play(turns[i], s.dice)
+				}
+			}
+		}()
+	}
+	for _, s := range shards {
+		next <- s
+	}
+	close(next)
+	wg.Wait()
+
+	for _, city := range reached {
+		city.shard = nil
+	}
+	m.apply(shards)
+	return m.playTurns(turns[cut:])
+}
+
+// apply applies what moves of the shards changed outside of their cities, in order of the turns
+func (m *GameMap) apply(shards []*shard) {
+	type change struct {
+		turn    int
+		event   *Event
+		transit *transit
+	}
+	var changes []change
+	for _, s := range shards {
+		for i := range s.events {
+			changes = append(changes, change{turn: s.eventsAt[i], event: &s.events[i]})
+		}
+		for i, t := range s.departed {
+			changes = append(changes, change{turn: s.leftAt[i], transit: t})
+		}
+		for _, city := range s.enrolled {
+			m.enroll(city)
+		}
+		for _, city := range s.ruined {
+			m.ruin(city)
+		}
+	}
+	// Changes of a group are in order already, and a turn belongs to one group
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].turn < changes[j].turn
+	})
+	for _, c := range changes {
+		if c.event != nil {
+			m.emit(*c.event)
+		} else {
+			m.transits = append(m.transits, c.transit)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestSplitmix(t *testing.T) {
+	a, b := splitmix(7), splitmix(7)
+	for i := 0; i < 100; i++ {
+		n := a.Int63()
+		assert.Equal(t, n, b.Int63())
+		assert.GreaterOrEqual(t, n, int64(0))
+	}
+	b.Seed(8)
+	assert.NotEqual(t, a.Int63(), b.Int63())
+}
+
+func TestGameMap_shards(t *testing.T) {
+	tests := []struct {
+		name   string
+		cities []string
+		want   [][]int // Turns of every group
+	}{
+		{name: "Far apart", cities: []string{"0-0", "4-0"}, want: [][]int{{0}, {1}}},
+		{name: "Reaching the same city", cities: []string{"0-0", "2-0"}, want: [][]int{{0, 1}}},
+		{name: "Neighbors", cities: []string{"3-0", "4-0"}, want: [][]int{{0, 1}}},
+		{name: "Chained", cities: []string{"0-0", "2-0", "4-0", "6-0"}, want: [][]int{{0, 1, 2, 3}}},
+		{name: "Groups in order of turns", cities: []string{"0-0", "6-0", "2-0"}, want: [][]int{{0, 2}, {1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := NewGridGameMap(7, 1)
+			m.SetSeed(0)
+			var turns []turn
+			for _, name := range tt.cities {
+				turns = append(turns, turn{alien: m.AlienIDs().NewAlien(), city: m.GetExistCity(name)})
+			}
+			shards, reached := m.shards(turns)
+			var got [][]int
+			for _, s := range shards {
+				got = append(got, s.turns)
+			}
+			assert.Equal(t, tt.want, got)
+			// Cities of a turn and their neighbors move with the group of the turn
+			for i, s := range shards {
+				for _, turn := range got[i] {
+					assert.Equal(t, s, turns[turn].city.shard)
+					for _, exit := range turns[turn].city.Exits() {
+						assert.Equal(t, s, exit.To.shard)
+					}
+				}
+			}
+			for _, city := range reached {
+				assert.NotNil(t, city.shard)
+			}
+		})
+	}
+}
+
+// playWithWorkers runs a game with most rules on, and long and one-way roads, moving aliens on given workers
+func playWithWorkers(build func() *GameMap, aliens int, workers int) (events []string, dump string, stats Stats) {
+	m := build()
+	m.SetWorkers(workers)
+	m.SetCombatResolver(StrengthResolver{RetreatChance: 0.2})
+	return play(m, aliens)
+}
+
+func TestGameMap_SetWorkers(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func() *GameMap
+		aliens int
+	}{
+		{
+			name: "Sample map",
+			build: func() *GameMap {
+				m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+				return m
+			},
+			aliens: 6,
+		},
+		{
+			name: "Graph map",
+			build: func() *GameMap {
+				m, _ := (&StreamParser{Graph: true}).ParseFile("test_resources/graph_map.txt")
+				return m
+			},
+			aliens: 3,
+		},
+		{
+			name: "Long and one-way roads",
+			build: func() *GameMap {
+				m, _ := (&StreamParser{}).ParseString("A east=B:3 south=C\nB south=D\nC east=D:2\nD east=>E\nE south=F:4\nF west=G\nG north=>D")
+				return m
+			},
+			aliens: 4,
+		},
+		{
+			name: "Grid map",
+			build: func() *GameMap {
+				m, _ := NewGridGameMap(30, 20)
+				for x := 3; x < 30; x += 3 {
+					_ = m.SetRoadLength(GridCityName(x, 5), West, GridCityName(x-1, 5), 2)
+				}
+				return m
+			},
+			aliens: 100,
+		},
+		{
+			name: "Compact grid map",
+			build: func() *GameMap {
+				m, _ := NewCompactGridGameMap(30, 20)
+				return m
+			},
+			aliens: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The classic engine is the reference
+			wantEvents, wantDump, wantStats := playWithWorkers(tt.build, tt.aliens, 0)
+			assert.NotEmpty(t, wantEvents)
+			for _, workers := range []int{1, 2, 8} {
+				events, dump, stats := playWithWorkers(tt.build, tt.aliens, workers)
+				assert.Equal(t, wantEvents, events, workers)
+				assert.Equal(t, wantDump, dump, workers)
+				assert.Equal(t, wantStats, stats, workers)
+			}
+		})
+	}
+}
+
+func TestGameMap_SetWorkersMaxSteps(t *testing.T) {
+	// Aliens going too far at different ticks, as some of them start later
+	run := func(workers int) (ticks int, steps []int, dump string, m *GameMap) {
+		m, _ = NewGridGameMap(10, 10)
+		m.SetSeed(5)
+		m.SetWorkers(workers)
+		m.SetMaxSteps(4)
+		m.SetCityCapacity(0)
+		aliens := make([]*Alien, 6)
+		for i := range aliens {
+			aliens[i] = m.AlienIDs().NewAlien()
+			aliens[i].Faction = "red"
+			aliens[i].Steps = i % 3
+		}
+		_ = m.AssignAliens(aliens)
+		ticks = 1
+		for m.Update() {
+			ticks++
+		}
+		for _, alien := range aliens {
+			steps = append(steps, alien.Steps)
+		}
+		return ticks, steps, m.DumpMap(), m
+	}
+	wantTicks, wantSteps, wantDump, _ := run(0)
+	assert.Equal(t, 3, wantTicks)
+	for _, workers := range []int{1, 2} {
+		ticks, steps, dump, m := run(workers)
+		// The game stops at the same turn as the classic engine, units after it don't move
+		assert.Equal(t, wantTicks, ticks)
+		assert.Equal(t, wantSteps, steps)
+		assert.Equal(t, wantDump, dump)
+		for _, city := range m.Cities() {
+			assert.Nil(t, city.shard)
+		}
+	}
+}
+
+func TestGameMap_SetWorkersWithPlayer(t *testing.T) {
+	tests := []struct {
+		name   string
+		player func(decide func(unit *Alien, from *City, exits []Exit) (Exit, bool)) MovementStrategy
+	}{
+		{name: "Player", player: func(decide func(unit *Alien, from *City, exits []Exit) (Exit, bool)) MovementStrategy {
+			return Player{Decide: decide}
+		}},
+		{name: "Pointer to a player", player: func(decide func(unit *Alien, from *City, exits []Exit) (Exit, bool)) MovementStrategy {
+			return &Player{Decide: decide}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := NewGridGameMap(10, 10)
+			m.SetSeed(0)
+			m.SetWorkers(4)
+			m.SetMaxTicks(20)
+			aliens := make([]*Alien, 10)
+			for i := range aliens {
+				aliens[i] = m.AlienIDs().NewAlien()
+			}
+			decisions := 0
+			aliens[0].Strategy = tt.player(func(unit *Alien, from *City, exits []Exit) (Exit, bool) {
+				// Cities don't move in groups, the player may read any of them
+				for _, city := range m.Cities() {
+					assert.Nil(t, city.shard)
+				}
+				decisions++
+				return exits[0], true
+			})
+			_ = m.AssignAliens(aliens)
+			for m.Update() {
+			}
+			assert.Greater(t, decisions, 0)
+		})
+	}
+}
+
+func BenchmarkGameMap_UpdateWorkers(b *testing.B) {
+	for _, workers := range []int{0, 1, 4} {
+		m, _ := NewCompactGridGameMap(1000, 1000)
+		m.SetSeed(0)
+		m.SetWorkers(workers)
+		m.SetMaxSteps(math.MaxInt)
+		m.SetCityCapacity(0)
+		aliens := make([]*Alien, 10000)
+		for i := range aliens {
+			aliens[i] = m.AlienIDs().NewAlien()
+			aliens[i].Faction = "red"
+		}
+		_ = m.AssignAliens(aliens)
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Update()
+			}
+		})
+	}
+}
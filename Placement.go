@@ -0,0 +1,263 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// PlacementStrategy picks the city a unit of given side lands in, among candidates it is allowed to land in.
+// Returns nil if none of the candidates suits the strategy. Pick is called for every unit, with all cities left to land in.
+type PlacementStrategy interface {
+	Pick(rng *rand.Rand, m *GameMap, side Side, candidates []*City) *City
+}
+
+// UniformPlacement is the classic rule : any city, uniformly random
+type UniformPlacement struct {
+}
+
+func (s UniformPlacement) Pick(rng *rand.Rand, m *GameMap, side Side, candidates []*City) *City {
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
+
+// ClusteredPlacement lands units as close as possible to cities already holding units of the same side.
+// Every pick walks all cities reachable from them, see distancesFrom.
+type ClusteredPlacement struct {
+}
+
+func (s ClusteredPlacement) Pick(rng *rand.Rand, m *GameMap, side Side, candidates []*City) *City {
+	distances := m.distancesFrom(m.occupiedBy(side))
+	return pickBest(rng, candidates, func(city *City) int {
+		if distance, reachable := distances[city]; reachable {
+			return -distance
+		}
+		return -len(m.cityList)
+	})
+}
+
+// SpreadPlacement lands units as far as possible from cities already holding units of the same side, cities out of reach first.
+// Every pick walks all cities reachable from them, see distancesFrom.
+type SpreadPlacement struct {
+}
+
+func (s SpreadPlacement) Pick(rng *rand.Rand, m *GameMap, side Side, candidates []*City) *City {
+	distances := m.distancesFrom(m.occupiedBy(side))
+	return pickBest(rng, candidates, func(city *City) int {
+		if distance, reachable := distances[city]; reachable {
+			return distance
+		}
+		return len(m.cityList)
+	})
+}
+
+// BorderPlacement only lands units in border cities, cities with fewer roads than the best connected city of the map.
+// Every pick walks all cities of the map.
+type BorderPlacement struct {
+}
+
+func (s BorderPlacement) Pick(rng *rand.Rand, m *GameMap, side Side, candidates []*City) *City {
+	var most int
+	for _, city := range m.Cities() {
+		if roads := len(city.Exits()); roads > most {
+			most = roads
+		}
+	}
+	var border []*City
+	for _, city := range candidates {
+		if len(city.Exits()) < most {
+			border = append(border, city)
+		}
+	}
+	return UniformPlacement{}.Pick(rng, m, side, border)
+}
+
+// WeightedPlacement picks cities randomly, weighted by an attribute : hp, defense, roads or defenders.
+// Cities weighted 0 are never picked, unless all of them are. Every pick weighs all candidates.
+type WeightedPlacement struct {
+	Attribute string
+}
+
+func (s WeightedPlacement) Pick(rng *rand.Rand, m *GameMap, side Side, candidates []*City) *City {
+	var total int
+	weights := make([]int, len(candidates))
+	for i, city := range candidates {
+		weights[i] = s.weight(city)
+		total += weights[i]
+	}
+	if total == 0 {
+		return UniformPlacement{}.Pick(rng, m, side, candidates)
+	}
+	r := rng.Intn(total)
+	for i, weight := range weights {
+		if r < weight {
+			return candidates[i]
+		}
+		r -= weight
+	}
+	return nil
+}
+
+func (s WeightedPlacement) weight(city *City) int {
+	switch s.Attribute {
+	case "hp":
+		return city.HitPoints
+	case "defense":
+		return city.Defense
+	case "roads":
+		return len(city.Exits())
+	case "defenders":
+		return city.Garrison
+	default:
+		return 0
+	}
+}
+
+// PlacementStrategyFromString returns one of the built-in placement strategies by name :
+// uniform, clustered, spread, border, or weighted:<attribute> with attribute hp, defense, roads or defenders
+func PlacementStrategyFromString(name string) (PlacementStrategy, error) {
+	if kind, attribute, found := strings.Cut(name, ":"); found && kind == "weighted" {
+		switch attribute {
+		case "hp", "defense", "roads", "defenders":
+			return WeightedPlacement{Attribute: attribute}, nil
+		default:
+			return nil, fmt.Errorf("unknown placement attribute %s", attribute)
+		}
+	}
+	switch name {
+	case "uniform":
+		return UniformPlacement{}, nil
+	case "clustered":
+		return ClusteredPlacement{}, nil
+	case "spread":
+		return SpreadPlacement{}, nil
+	case "border":
+		return BorderPlacement{}, nil
+	default:
+		return nil, fmt.Errorf("unknown placement strategy %s", name)
+	}
+}
+
+// SetPlacementStrategy changes how AssignAliens and spawn waves pick cities, uniformly random by default
+func (m *GameMap) SetPlacementStrategy(strategy PlacementStrategy) {
+	m.placement = strategy
+}
+
+// landingPool holds cities units can still land in. Cities leave the pool once they can't take more units,
+// so placing n units costs one scan of the cities and n picks, instead of one scan for every unit.
+// Only uniform picks take constant time : other strategies get all cities of the pool on every pick,
+// so they place n units in O(n × cities) and materialize every city of a compact map.
+type landingPool struct {
+	m   *GameMap
+	ids []int32
+}
+
+// newLandingPool makes a pool of cities units can land in, among cities of given IDs
+func (m *GameMap) newLandingPool(ids []int32) *landingPool {
+	pool := &landingPool{m: m}
+	for _, id := range ids {
+		if m.canLand(int(id)) {
+			pool.ids = append(pool.ids, id)
+		}
+	}
+	return pool
+}
+
+// land picks a city for the unit by the placement strategy and lands it, nil if no city suits
+func (p *landingPool) land(alien *Alien) *City {
+	index := p.pick(alien.Side)
+	if index < 0 {
+		return nil
+	}
+	return p.take(index, alien)
+}
+
+// take lands the unit in the city at index of the pool
+func (p *landingPool) take(index int, alien *Alien) *City {
+	city := p.m.city(int(p.ids[index]))
+	p.m.land(alien, city)
+	// The city may be full, or destroyed by aliens gathering in it
+	if !p.m.canLand(int(p.ids[index])) {
+		// Order of the pool doesn't matter, swap with the last one instead of shifting
+		last := len(p.ids) - 1
+		p.ids[index] = p.ids[last]
+		p.ids = p.ids[:last]
+	}
+	return city
+}
+
+// pick returns index of the city picked for a unit of given side, -1 if none
+func (p *landingPool) pick(side Side) int {
+	if len(p.ids) == 0 {
+		return -1
+	}
+	switch p.m.placement.(type) {
+	case nil, UniformPlacement:
+		return p.m.Rand().Intn(len(p.ids))
+	}
+	candidates := make([]*City, len(p.ids))
+	for i, id := range p.ids {
+		candidates[i] = p.m.city(int(id))
+	}
+	city := p.m.placement.Pick(p.m.Rand(), p.m, side, candidates)
+	for i, candidate := range candidates {
+		if candidate == city {
+			return i
+		}
+	}
+	return -1
+}
+
+// occupiedBy lists cities holding live units of given side
+func (m *GameMap) occupiedBy(side Side) []*City {
+	var occupied []*City
+	for _, city := range m.occupiedCities() {
+		for _, alien := range city.Aliens {
+			if alien.Alive && alien.Side == side {
+				occupied = append(occupied, city)
+				break
+			}
+		}
+	}
+	return occupied
+}
+
+// distancesFrom counts roads from the nearest of given cities to every city reachable, through cities still exist.
+// Costs O(cities + roads) reachable, whatever the number of sources : the roster only saves finding the sources.
+func (m *GameMap) distancesFrom(sources []*City) map[*City]int {
+	distances := make(map[*City]int)
+	queue := make([]*City, 0, len(sources))
+	for _, city := range sources {
+		distances[city] = 0
+		queue = append(queue, city)
+	}
+	for len(queue) > 0 {
+		city := queue[0]
+		queue = queue[1:]
+		for _, exit := range city.Exits() {
+			if _, seen := distances[exit.To]; seen || !exit.To.Exists {
+				continue
+			}
+			distances[exit.To] = distances[city] + 1
+			queue = append(queue, exit.To)
+		}
+	}
+	return distances
+}
+
+// pickBest picks a random city among candidates with the highest score
+func pickBest(rng *rand.Rand, candidates []*City, score func(*City) int) *City {
+	var best []*City
+	var bestScore int
+	for _, city := range candidates {
+		switch s := score(city); {
+		case len(best) == 0 || s > bestScore:
+			best, bestScore = []*City{city}, s
+		case s == bestScore:
+			best = append(best, city)
+		}
+	}
+	return UniformPlacement{}.Pick(rng, nil, Invaders, best)
+}
@@ -0,0 +1,165 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestPlacementStrategyFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    PlacementStrategy
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "uniform", want: UniformPlacement{}, wantErr: assert.NoError},
+		{name: "clustered", want: ClusteredPlacement{}, wantErr: assert.NoError},
+		{name: "spread", want: SpreadPlacement{}, wantErr: assert.NoError},
+		{name: "border", want: BorderPlacement{}, wantErr: assert.NoError},
+		{name: "weighted:hp", want: WeightedPlacement{Attribute: "hp"}, wantErr: assert.NoError},
+		{name: "weighted:roads", want: WeightedPlacement{Attribute: "roads"}, wantErr: assert.NoError},
+		{name: "weighted:beauty", want: nil, wantErr: assert.Error},
+		{name: "everywhere", want: nil, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PlacementStrategyFromString(tt.name)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlacementStrategy_Pick(t *testing.T) {
+	// A line of cities, Foo - Bar - Baz - Qux - Quux, Bar is fortified and Qux is occupied
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar\nBar east=Baz hp=5\nBaz east=Qux\nQux east=Quux")
+	m.cities["Qux"].Aliens = []*Alien{{Number: 0, Alive: true}}
+	candidates := []*City{m.cities["Foo"], m.cities["Bar"], m.cities["Baz"], m.cities["Quux"]}
+	rng := rand.New(rand.NewSource(0))
+
+	tests := []struct {
+		name     string
+		strategy PlacementStrategy
+		side     Side
+		want     []string
+	}{
+		{
+			name:     "Uniform picks any city",
+			strategy: UniformPlacement{},
+			want:     []string{"Foo", "Bar", "Baz", "Quux"},
+		},
+		{
+			name:     "Clustered picks neighbors of occupied cities",
+			strategy: ClusteredPlacement{},
+			want:     []string{"Baz", "Quux"},
+		},
+		{
+			name:     "Spread picks the farthest city",
+			strategy: SpreadPlacement{},
+			want:     []string{"Foo"},
+		},
+		{
+			name:     "Other side is not occupying anything",
+			strategy: SpreadPlacement{},
+			side:     Defenders,
+			want:     []string{"Foo", "Bar", "Baz", "Quux"},
+		},
+		{
+			name:     "Border picks ends of the line",
+			strategy: BorderPlacement{},
+			want:     []string{"Foo", "Quux"},
+		},
+		{
+			name:     "Weighted picks cities with the attribute",
+			strategy: WeightedPlacement{Attribute: "hp"},
+			want:     []string{"Foo", "Bar", "Baz", "Quux"},
+		},
+		{
+			name:     "Weighted without the attribute picks any city",
+			strategy: WeightedPlacement{Attribute: "defense"},
+			want:     []string{"Foo", "Bar", "Baz", "Quux"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got := tt.strategy.Pick(rng, m, tt.side, candidates)
+				assert.Contains(t, tt.want, got.Name)
+			}
+		})
+	}
+}
+
+func TestWeightedPlacement_Pick(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar defenders=1\nBar")
+	rng := rand.New(rand.NewSource(0))
+	for i := 0; i < 20; i++ {
+		got := WeightedPlacement{Attribute: "defenders"}.Pick(rng, m, Invaders, m.Cities())
+		assert.Equal(t, "Foo", got.Name)
+	}
+}
+
+func TestBorderPlacement_Pick(t *testing.T) {
+	// Every city of a ring has as many roads as the others
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar\nBar east=Baz\nBaz east=Foo")
+	assert.Nil(t, BorderPlacement{}.Pick(rand.New(rand.NewSource(0)), m, Invaders, m.Cities()))
+
+	m.SetPlacementStrategy(BorderPlacement{})
+	assert.Error(t, m.AssignAliens([]*Alien{m.AlienIDs().NewAlien()}))
+}
+
+func TestLandingPool_Land(t *testing.T) {
+	tests := []struct {
+		name      string
+		stacked   bool
+		placement PlacementStrategy
+		wantLands int
+	}{
+		{name: "One alien in each city", wantLands: 4},
+		{name: "Stacked up to capacity", stacked: true, wantLands: 6},
+		{name: "Placement strategy", placement: SpreadPlacement{}, wantLands: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("Foo north=Bar capacity=3\nBaz south=Bar\nQux")
+			m.SetStackedPlacement(tt.stacked)
+			m.SetPlacementStrategy(tt.placement)
+			pool := m.newLandingPool(m.cityIDs())
+			lands := 0
+			for pool.land(m.AlienIDs().NewAlien()) != nil {
+				lands++
+			}
+			assert.Equal(t, tt.wantLands, lands)
+			for _, city := range m.Cities() {
+				assert.LessOrEqual(t, len(city.Aliens), city.capacity())
+			}
+		})
+	}
+}
+
+func BenchmarkGameMap_AssignAliensPlacement(b *testing.B) {
+	for _, name := range []string{"uniform", "clustered", "spread", "border", "weighted:roads"} {
+		placement, _ := PlacementStrategyFromString(name)
+		for _, size := range []struct{ width, aliens int }{{30, 100}, {100, 300}} {
+			m, _ := NewGridGameMap(size.width, size.width)
+			m.SetPlacementStrategy(placement)
+			b.Run(fmt.Sprintf("%s/%d aliens in %d cities", name, size.aliens, size.width*size.width), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					for _, city := range m.cityList {
+						city.Aliens = nil
+					}
+					aliens := make([]*Alien, size.aliens)
+					for j := range aliens {
+						aliens[j] = m.AlienIDs().NewAlien()
+					}
+					b.StartTimer()
+					if err := m.AssignAliens(aliens); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
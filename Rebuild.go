@@ -0,0 +1,70 @@
+package alien_invastion
+
+// SetRebuildDelay lets destroyed cities recover after given ticks, 0 to disable.
+// A city is only rebuilt while no invader is in its ruins, in a neighbor city, or on its roads.
+func (m *GameMap) SetRebuildDelay(ticks int) {
+	m.rebuildDelay = ticks
+}
+
+// destroy marks the city destroyed at current tick
+func (c *City) destroy() {
+	c.Exists = false
+	if c.gameMap != nil {
+		c.DestroyedAt = c.gameMap.tick
+		c.gameMap.ruin(c)
+	}
+}
+
+// rebuild recovers destroyed cities waited long enough, and their destroyed roads to cities still exist
+func (m *GameMap) rebuild() {
+	if m.rebuildDelay <= 0 {
+		return
+	}
+	for _, city := range m.ruinedCities() {
+		if city.Exists || m.tick-city.DestroyedAt < m.rebuildDelay || m.threatened(city) {
+			continue
+		}
+		city.rebuilt()
+		m.emit(Event{Type: CityRebuilt, City: city.Name})
+		city.wire()
+		for _, road := range city.Roads {
+			if road.Destroyed && road.Other(city).Exists {
+				road.Destroyed = false
+				m.emit(Event{Type: RoadRebuilt, From: road.From.Name, To: road.To.Name})
+			}
+		}
+	}
+}
+
+// rebuilt starts the city over, like it was declared by the map
+func (c *City) rebuilt() {
+	c.Exists = true
+	c.HitPoints = 1
+	if c.hitPoints > 0 {
+		c.HitPoints = c.hitPoints
+	}
+	c.ControlledBy = ""
+}
+
+// threatened tells if an invader is in the city, in a neighbor city, or on a road of the city
+func (m *GameMap) threatened(city *City) bool {
+	for _, alien := range city.Aliens {
+		if alien.Alive && alien.Side == Invaders {
+			return true
+		}
+	}
+	city.wire()
+	for _, road := range city.Roads {
+		for _, alien := range road.Other(city).Aliens {
+			if alien.Alive && alien.Side == Invaders {
+				return true
+			}
+		}
+	}
+	for _, t := range m.transits {
+		if t.alien.Alive && t.alien.Side == Invaders && (t.from == city || t.to == city) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGameMap_rebuild(t *testing.T) {
+	tests := []struct {
+		name       string
+		delay      int
+		ticks      int
+		patch      func(m *GameMap)
+		wantExists bool
+		wantEvents []EventType
+	}{
+		{
+			name:       "Disabled",
+			delay:      0,
+			ticks:      10,
+			wantExists: false,
+		},
+		{
+			name:       "Not yet",
+			delay:      3,
+			ticks:      2,
+			wantExists: false,
+		},
+		{
+			name:       "Rebuilt with its roads",
+			delay:      3,
+			ticks:      3,
+			wantExists: true,
+			wantEvents: []EventType{CityRebuilt, RoadRebuilt},
+		},
+		{
+			name:  "Alien in a neighbor city",
+			delay: 3,
+			ticks: 10,
+			patch: func(m *GameMap) {
+				m.cities["Baz"].Aliens = []*Alien{{Alive: true}}
+			},
+			wantExists: false,
+		},
+		{
+			name:  "Alien stuck in ruins",
+			delay: 3,
+			ticks: 10,
+			patch: func(m *GameMap) {
+				m.cities["Bar"].Aliens = []*Alien{{Alive: true}}
+			},
+			wantExists: false,
+		},
+		{
+			name:  "Defender in a neighbor city",
+			delay: 3,
+			ticks: 3,
+			patch: func(m *GameMap) {
+				m.cities["Baz"].Aliens = []*Alien{{Alive: true, Side: Defenders}}
+			},
+			wantExists: true,
+			wantEvents: []EventType{CityRebuilt, RoadRebuilt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("Foo north=Bar\nBar hp=3 north=Baz")
+			m.SetRebuildDelay(tt.delay)
+			assert.NoError(t, m.destroyCity("Bar"))
+			assert.NoError(t, m.DestroyRoad("Bar", "Foo"))
+			if tt.patch != nil {
+				tt.patch(m)
+			}
+			for i := 0; i < tt.ticks; i++ {
+				m.tick++
+				m.rebuild()
+			}
+			bar := m.cities["Bar"]
+			assert.Equal(t, tt.wantExists, bar.Exists)
+			var events []EventType
+			for _, e := range m.Events()[1:] {
+				events = append(events, e.Type)
+			}
+			assert.Equal(t, tt.wantEvents, events)
+			if tt.wantExists {
+				// Hit points declared by the map are back
+				assert.Equal(t, 3, bar.HitPoints)
+				assert.False(t, bar.Roads[0].Destroyed)
+				assert.Contains(t, m.DumpMap(), "Bar hp=3 north=Baz south=Foo")
+			}
+		})
+	}
+}
+
+func TestCity_rebuilt(t *testing.T) {
+	tests := []struct {
+		name          string
+		m             string
+		compact       bool
+		wantHitPoints int
+	}{
+		{name: "Hit points not declared", m: "Foo north=Bar", wantHitPoints: 1},
+		{name: "Hit points declared", m: "Foo hp=5 north=Bar", wantHitPoints: 5},
+		{name: "Hit points declared in a compact map", m: "Foo hp=5 north=Bar", compact: true, wantHitPoints: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{Compact: tt.compact}).ParseString(tt.m)
+			foo := m.GetExistCity("Foo")
+			foo.HitPoints = 0
+			foo.ControlledBy = "red"
+			foo.destroy()
+			foo.rebuilt()
+			assert.True(t, foo.Exists)
+			assert.Equal(t, tt.wantHitPoints, foo.HitPoints)
+			assert.Empty(t, foo.ControlledBy)
+		})
+	}
+}
+
+func TestCity_destroy(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar")
+	m.tick = 7
+	m.cities["Foo"].destroy()
+	assert.False(t, m.cities["Foo"].Exists)
+	assert.Equal(t, 7, m.cities["Foo"].DestroyedAt)
+}
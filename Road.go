@@ -0,0 +1,200 @@
+package alien_invastion
+
+import "fmt"
+
+// Road connects two cities. Roads of a compass map are labelled by their direction, roads of a graph map by an optional name.
+type Road struct {
+	Name      string
+	Direction Direction // Direction from From to To, Invalid on graph maps
+	From      *City
+	To        *City
+	OneWay    bool // One-way roads can only be taken from From to To
+	Length    int  // Ticks needed to walk through the road, 0 means not specified (1 tick)
+	Destroyed bool // Destroyed roads are no longer exits, but still connect cities in Neighborhoods
+}
+
+// TravelTime returns ticks needed to walk through the road, at least 1
+func (r *Road) TravelTime() int {
+	if r.Length < 1 {
+		return 1
+	}
+	return r.Length
+}
+
+// Other returns the city on the other side of the road, or nil if the city is not on this road
+func (r *Road) Other(c *City) *City {
+	switch c {
+	case r.From:
+		return r.To
+	case r.To:
+		return r.From
+	default:
+		return nil
+	}
+}
+
+// Exit is a road seen from one of its cities : where it leads, and how it is labelled from this side
+type Exit struct {
+	Road      *Road
+	To        *City
+	Direction Direction // Invalid on graph maps
+}
+
+// String prints the exit as it would be declared in a compass map file, or as an entry of a graph map line
+func (e Exit) String() string {
+	to := e.To.Name
+	if e.Road.TravelTime() > 1 {
+		to = fmt.Sprintf("%s:%d", to, e.Road.TravelTime())
+	}
+	switch {
+	case e.Direction != Invalid && e.Road.OneWay:
+		return fmt.Sprintf("%s=>%s", e.Label(), to)
+	case e.Label() != "":
+		return fmt.Sprintf("%s=%s", e.Label(), to)
+	default:
+		return to
+	}
+}
+
+// Label is the text that describes the exit in map files, a direction or a road name (might be empty for anonymous roads)
+func (e Exit) Label() string {
+	if e.Direction != Invalid {
+		return e.Direction.String()
+	}
+	return e.Road.Name
+}
+
+// Exits lists all roads leading out of the city, no matter the city on the other side still exists or not.
+// One-way roads leading into the city and destroyed roads are not exits.
+// Cities wired by hand (without GameMap) have no roads, so their exits are taken from Neighborhoods.
+func (c *City) Exits() []Exit {
+	c.wire()
+	var exits []Exit
+	if len(c.Roads) == 0 {
+		for direction, city := range c.Neighborhoods {
+			if city != nil {
+				road := &Road{Direction: Direction(direction), From: c, To: city}
+				exits = append(exits, Exit{Road: road, To: city, Direction: Direction(direction)})
+			}
+		}
+		return exits
+	}
+
+	for _, road := range c.Roads {
+		if road.Destroyed || road.OneWay && road.From != c {
+			continue
+		}
+		exit := Exit{Road: road, To: road.Other(c), Direction: road.Direction}
+		if road.To == c && road.Direction != Invalid {
+			exit.Direction = road.Direction.GetOpposite()
+		}
+		exits = append(exits, exit)
+	}
+	return exits
+}
+
+// roadTo finds the road from this city to another one in given direction. Use Invalid for graph roads.
+func (c *City) roadTo(to *City, direction Direction) *Road {
+	c.wire()
+	for _, road := range c.Roads {
+		if road.From == c && road.To == to && road.Direction == direction {
+			return road
+		}
+		if road.From == to && road.To == c && road.Direction == direction.GetOpposite() {
+			return road
+		}
+	}
+	return nil
+}
+
+// connect links two cities with a new road, unless they are already linked in this direction.
+// A one-way road declared against an existing one-way road in reverse makes it a two-way road.
+func (c *City) connect(to *City, direction Direction, name string, oneWay bool) (*Road, error) {
+	to.wire()
+	road := c.roadTo(to, direction)
+	if road == nil {
+		road = &Road{Name: name, Direction: direction, From: c, To: to, OneWay: oneWay}
+		c.Roads = append(c.Roads, road)
+		to.Roads = append(to.Roads, road)
+		if c.gameMap != nil {
+			c.gameMap.roads = append(c.gameMap.roads, road)
+		}
+		return road, nil
+	}
+
+	switch {
+	case road.OneWay && !oneWay:
+		return nil, fmt.Errorf("road from %s to %s is one-way, but declared as two-way (conflict)", road.From.Name, road.To.Name)
+	case !road.OneWay && oneWay:
+		return nil, fmt.Errorf("road between %s and %s is two-way, but declared as one-way (conflict)", c.Name, to.Name)
+	case road.OneWay && road.From == to:
+		road.OneWay = false
+	}
+	return road, nil
+}
+
+// DestroyRoad destroys all roads between two cities, aliens walking on them are killed.
+// Error if no such road or already destroyed.
+func (m *GameMap) DestroyRoad(name string, neighborhoodCityName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	city, neighborhoodCity := m.lookup(name), m.lookup(neighborhoodCityName)
+	if city == nil || neighborhoodCity == nil {
+		return fmt.Errorf("no road between %s and %s", name, neighborhoodCityName)
+	}
+	city.wire()
+	var found bool
+	for _, road := range city.Roads {
+		if road.Other(city) != neighborhoodCity {
+			continue
+		}
+		found = true
+		if !road.Destroyed {
+			m.destroyRoad(road)
+			return nil
+		}
+	}
+	if found {
+		return fmt.Errorf("road between %s and %s have been already destroyed", name, neighborhoodCityName)
+	}
+	return fmt.Errorf("no road between %s and %s", name, neighborhoodCityName)
+}
+
+// SetRoadFailureRate sets the chance of each road being destroyed in every tick, 0 to disable
+func (m *GameMap) SetRoadFailureRate(rate float64) {
+	m.roadFailureRate = rate
+}
+
+// destroyRoad destroys the road, and kill aliens walking on it. Aliens destroying the road are not killed again.
+func (m *GameMap) destroyRoad(road *Road, byAliens ...*Alien) {
+	road.Destroyed = true
+	var numbers []int
+	for _, alien := range byAliens {
+		numbers = append(numbers, alien.Number)
+	}
+	m.emit(Event{Type: RoadDestroyed, From: road.From.Name, To: road.To.Name, Aliens: numbers})
+
+	var walking []*transit
+	for _, t := range m.transits {
+		if t.road == road && t.alien.Alive {
+			t.alien.Alive = false
+			m.emit(Event{Type: AlienKilled, From: road.From.Name, To: road.To.Name, Aliens: []int{t.alien.Number}})
+		}
+		if t.alien.Alive {
+			walking = append(walking, t)
+		}
+	}
+	m.transits = walking
+}
+
+// failRoads randomly destroys roads according to road failure rate
+func (m *GameMap) failRoads() {
+	if m.roadFailureRate <= 0 {
+		return
+	}
+	for i := 0; i < m.roadCount(); i++ {
+		if !m.roadDestroyed(i) && m.Rand().Float64() < m.roadFailureRate {
+			m.destroyRoad(m.roadAt(i))
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRoad_Other(t *testing.T) {
+	from, to := newCity("city1"), newCity("city2")
+	road := &Road{Direction: North, From: from, To: to}
+	assert.Equal(t, to, road.Other(from))
+	assert.Equal(t, from, road.Other(to))
+	assert.Nil(t, road.Other(newCity("city3")))
+}
+
+func TestCity_Exits(t *testing.T) {
+	tests := []struct {
+		name       string
+		city       func() *City
+		wantLabels []string
+	}{
+		{
+			name: "Hand wired neighborhoods",
+			city: func() *City {
+				c := newCity("city1")
+				c.Neighborhoods[North] = newCity("city2")
+				c.Neighborhoods[East] = newCity("city3")
+				return c
+			},
+			wantLabels: []string{"north=city2", "east=city3"},
+		},
+		{
+			name: "Compass roads seen from both sides",
+			city: func() *City {
+				m := NewGameMap()
+				_ = m.UpdateCityWithNeighborhood("city1", North, "city2")
+				_ = m.UpdateCityWithNeighborhood("city3", West, "city2")
+				return m.cities["city2"]
+			},
+			wantLabels: []string{"south=city1", "east=city3"},
+		},
+		{
+			name: "Graph roads",
+			city: func() *City {
+				m := NewGraphGameMap()
+				_ = m.ConnectCities("city1", "", "city2")
+				_ = m.ConnectCities("city3", "highway", "city2")
+				return m.cities["city2"]
+			},
+			wantLabels: []string{"=city1", "highway=city3"},
+		},
+		{
+			name: "One-way roads only lead out of their origin",
+			city: func() *City {
+				m := NewGameMap()
+				_ = m.UpdateCityWithOneWayRoad("city1", North, "city2")
+				_ = m.UpdateCityWithOneWayRoad("city2", East, "city3")
+				return m.cities["city2"]
+			},
+			wantLabels: []string{"east=city3"},
+		},
+		{
+			name: "Destroyed roads are not exits",
+			city: func() *City {
+				m := NewGameMap()
+				_ = m.UpdateCityWithNeighborhood("city1", North, "city2")
+				_ = m.UpdateCityWithNeighborhood("city1", East, "city3")
+				_ = m.DestroyRoad("city3", "city1")
+				return m.cities["city1"]
+			},
+			wantLabels: []string{"north=city2"},
+		},
+		{
+			name: "No exits",
+			city: func() *City {
+				return newCity("city1")
+			},
+			wantLabels: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var labels []string
+			for _, exit := range tt.city().Exits() {
+				labels = append(labels, fmt.Sprintf("%s=%s", exit.Label(), exit.To.Name))
+			}
+			assert.Equal(t, tt.wantLabels, labels)
+		})
+	}
+}
+
+func TestGameMap_ConnectCities(t *testing.T) {
+	type args struct {
+		name                 string
+		roadName             string
+		neighborhoodCityName string
+	}
+	tests := []struct {
+		name     string
+		gameMap  *GameMap
+		patch    func(t *testing.T, gameMap *GameMap)
+		args     args
+		wantErr  assert.ErrorAssertionFunc
+		validate func(t *testing.T, gameMap *GameMap)
+	}{
+		{
+			name:    "Good - Anonymous road",
+			gameMap: NewGraphGameMap(),
+			args: args{
+				name:                 "city1",
+				neighborhoodCityName: "city2",
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, 1, len(gameMap.cities["city1"].Roads))
+				assert.Equal(t, gameMap.cities["city1"].Roads[0], gameMap.cities["city2"].Roads[0])
+			},
+		},
+		{
+			name:    "Good - Declared again from other side names the road",
+			gameMap: NewGraphGameMap(),
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.ConnectCities("city1", "", "city2")
+			},
+			args: args{
+				name:                 "city2",
+				roadName:             "highway",
+				neighborhoodCityName: "city1",
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, 1, len(gameMap.cities["city1"].Roads))
+				assert.Equal(t, "highway", gameMap.cities["city1"].Roads[0].Name)
+			},
+		},
+		{
+			name:    "Good - More than four neighbors",
+			gameMap: NewGraphGameMap(),
+			patch: func(t *testing.T, gameMap *GameMap) {
+				for i := 2; i < 7; i++ {
+					_ = gameMap.ConnectCities("city1", "", fmt.Sprintf("city%d", i))
+				}
+			},
+			args: args{
+				name:                 "city1",
+				neighborhoodCityName: "city7",
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, 6, len(gameMap.cities["city1"].Exits()))
+			},
+		},
+		{
+			name:    "Bad - Road name conflict",
+			gameMap: NewGraphGameMap(),
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.ConnectCities("city1", "bridge", "city2")
+			},
+			args: args{
+				name:                 "city2",
+				roadName:             "highway",
+				neighborhoodCityName: "city1",
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, "bridge", gameMap.cities["city1"].Roads[0].Name)
+			},
+		},
+		{
+			name:    "Bad - Road to itself",
+			gameMap: NewGraphGameMap(),
+			args: args{
+				name:                 "city1",
+				neighborhoodCityName: "city1",
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, 0, len(gameMap.cities))
+			},
+		},
+		{
+			name:    "Bad - Compass map",
+			gameMap: NewGameMap(),
+			args: args{
+				name:                 "city1",
+				neighborhoodCityName: "city2",
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, 0, len(gameMap.cities))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.patch != nil {
+				tt.patch(t, tt.gameMap)
+			}
+			tt.wantErr(t, tt.gameMap.ConnectCities(tt.args.name, tt.args.roadName, tt.args.neighborhoodCityName), fmt.Sprintf("ConnectCities(%v, %v, %v)", tt.args.name, tt.args.roadName, tt.args.neighborhoodCityName))
+			tt.validate(t, tt.gameMap)
+		})
+	}
+}
+
+func TestCity_connect(t *testing.T) {
+	tests := []struct {
+		name        string
+		patch       func(from, to *City)
+		direction   Direction
+		oneWay      bool
+		wantErr     assert.ErrorAssertionFunc
+		wantRoads   int
+		wantOneWay  bool
+		wantReverse bool
+	}{
+		{
+			name:       "New one-way road",
+			direction:  North,
+			oneWay:     true,
+			wantErr:    assert.NoError,
+			wantRoads:  1,
+			wantOneWay: true,
+		},
+		{
+			name: "Same two-way road declared twice",
+			patch: func(from, to *City) {
+				_, _ = to.connect(from, South, "", false)
+			},
+			direction:   North,
+			wantErr:     assert.NoError,
+			wantRoads:   1,
+			wantReverse: true,
+		},
+		{
+			name: "Reverse one-way roads make a two-way road",
+			patch: func(from, to *City) {
+				_, _ = to.connect(from, South, "", true)
+			},
+			direction:   North,
+			oneWay:      true,
+			wantErr:     assert.NoError,
+			wantRoads:   1,
+			wantReverse: true,
+		},
+		{
+			name: "Two-way over one-way",
+			patch: func(from, to *City) {
+				_, _ = to.connect(from, South, "", true)
+			},
+			direction:   North,
+			wantErr:     assert.Error,
+			wantRoads:   1,
+			wantOneWay:  true,
+			wantReverse: true,
+		},
+		{
+			name: "One-way over two-way",
+			patch: func(from, to *City) {
+				_, _ = from.connect(to, North, "", false)
+			},
+			direction: North,
+			oneWay:    true,
+			wantErr:   assert.Error,
+			wantRoads: 1,
+		},
+		{
+			name: "Another direction is another road",
+			patch: func(from, to *City) {
+				_, _ = from.connect(to, North, "", false)
+			},
+			direction:  East,
+			oneWay:     true,
+			wantErr:    assert.NoError,
+			wantRoads:  2,
+			wantOneWay: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := newCity("city1"), newCity("city2")
+			if tt.patch != nil {
+				tt.patch(from, to)
+			}
+			_, err := from.connect(to, tt.direction, "", tt.oneWay)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantRoads, len(from.Roads))
+			road := from.Roads[len(from.Roads)-1]
+			assert.Equal(t, tt.wantOneWay, road.OneWay)
+			assert.Equal(t, tt.wantReverse, road.From == to)
+		})
+	}
+}
+
+func TestGameMap_DestroyRoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		patch    func(t *testing.T, gameMap *GameMap)
+		wantErr  assert.ErrorAssertionFunc
+		validate func(t *testing.T, gameMap *GameMap)
+	}{
+		{
+			name:    "Good - Destroy road",
+			from:    "Foo",
+			to:      "Bar",
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.True(t, gameMap.cities["Bar"].IsIsolatedOrDestroyed())
+				assert.False(t, gameMap.cities["Foo"].IsIsolatedOrDestroyed())
+				assert.Equal(t, RoadDestroyed, gameMap.Events()[0].Type)
+				// Cities are still there
+				assert.Equal(t, 3, gameMap.ExistCityCount())
+			},
+		},
+		{
+			name: "Good - Kills aliens walking on it",
+			from: "Bar",
+			to:   "Foo",
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.SetRoadLength("Foo", North, "Bar", 5)
+				alien := &Alien{Number: 7, Alive: true}
+				gameMap.cities["Foo"].Aliens = []*Alien{alien}
+				gameMap.depart(alien, gameMap.cities["Foo"], gameMap.cities["Foo"].Exits()[0])
+			},
+			wantErr: assert.NoError,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Empty(t, gameMap.InTransit())
+				assert.Equal(t, 2, len(gameMap.Events()))
+				assert.Equal(t, Event{Type: AlienKilled, From: "Foo", To: "Bar", Aliens: []int{7}}, gameMap.Events()[1])
+			},
+		},
+		{
+			name: "Bad - Already destroyed",
+			from: "Foo",
+			to:   "Bar",
+			patch: func(t *testing.T, gameMap *GameMap) {
+				_ = gameMap.DestroyRoad("Foo", "Bar")
+			},
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Equal(t, 1, len(gameMap.Events()))
+			},
+		},
+		{
+			name:    "Bad - No such road",
+			from:    "Bar",
+			to:      "Baz",
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Empty(t, gameMap.Events())
+			},
+		},
+		{
+			name:    "Bad - No such city",
+			from:    "Bar",
+			to:      "Qu-ux",
+			wantErr: assert.Error,
+			validate: func(t *testing.T, gameMap *GameMap) {
+				assert.Empty(t, gameMap.Events())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("Foo north=Bar west=Baz")
+			if tt.patch != nil {
+				tt.patch(t, m)
+			}
+			tt.wantErr(t, m.DestroyRoad(tt.from, tt.to), "DestroyRoad(%v, %v)", tt.from, tt.to)
+			tt.validate(t, m)
+		})
+	}
+}
+
+func TestGameMap_SetRoadFailureRate(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar west=Baz")
+	m.cities["Foo"].Aliens = []*Alien{{Number: 0, Alive: true}}
+	m.SetRoadFailureRate(1)
+	m.Update()
+	assert.Equal(t, 2, len(m.Events()))
+	assert.True(t, m.cities["Foo"].IsIsolatedOrDestroyed())
+	assert.Equal(t, m.cities["Foo"], m.GetExistCity("Foo"))
+	assert.Equal(t, "Foo ", m.formatCity(m.cities["Foo"]))
+}
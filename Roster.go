@@ -0,0 +1,95 @@
+package alien_invastion
+
+import "sort"
+
+// roster keeps track of cities holding units and of destroyed cities, so a tick costs O(units) instead of O(cities).
+// It is built from the cities on first use, then kept up to date as units land and move, and as cities are destroyed.
+// Aliens and Exists of a city should not be set by hand once the game started.
+type roster struct {
+	ready    bool
+	occupied []*City // Cities holding units, emptied ones are dropped on next lookup
+	ruins    []*City // Destroyed cities, rebuilt ones are dropped on next lookup
+}
+
+// buildRoster scans the cities once, for units and ruins placed before the roster is kept up to date
+func (m *GameMap) buildRoster() {
+	if m.roster.ready {
+		return
+	}
+	m.roster.ready = true
+	for id, city := range m.cityList {
+		if city == nil && !m.cityExists(id) {
+			city = m.city(id)
+		}
+		if city == nil {
+			continue
+		}
+		if len(city.Aliens) > 0 {
+			m.enroll(city)
+		}
+		if !city.Exists {
+			m.ruin(city)
+		}
+	}
+}
+
+// enroll adds the city to the roster of cities holding units
+func (m *GameMap) enroll(city *City) {
+	if city.shard != nil {
+		city.shard.enrolled = append(city.shard.enrolled, city)
+		return
+	}
+	if m.roster.ready && !city.enrolled {
+		city.enrolled = true
+		m.roster.occupied = append(m.roster.occupied, city)
+	}
+}
+
+// ruin adds the city to the roster of destroyed cities
+func (m *GameMap) ruin(city *City) {
+	if city.shard != nil {
+		city.shard.ruined = append(city.shard.ruined, city)
+		return
+	}
+	if m.roster.ready && !city.ruined {
+		city.ruined = true
+		m.roster.ruins = append(m.roster.ruins, city)
+	}
+}
+
+// occupiedCities returns cities holding units, in order of the map
+func (m *GameMap) occupiedCities() []*City {
+	m.buildRoster()
+	m.roster.occupied = prune(m.roster.occupied, func(city *City) bool {
+		city.enrolled = len(city.Aliens) > 0
+		return city.enrolled
+	})
+	return m.roster.occupied
+}
+
+// ruinedCities returns destroyed cities, in order of the map
+func (m *GameMap) ruinedCities() []*City {
+	m.buildRoster()
+	m.roster.ruins = prune(m.roster.ruins, func(city *City) bool {
+		city.ruined = !city.Exists
+		return city.ruined
+	})
+	return m.roster.ruins
+}
+
+// prune keeps cities in place, and sorts them in order of the map
+func prune(cities []*City, keep func(*City) bool) []*City {
+	kept := cities[:0]
+	for _, city := range cities {
+		if keep(city) {
+			kept = append(kept, city)
+		}
+	}
+	for i := len(kept); i < len(cities); i++ {
+		cities[i] = nil
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].index < kept[j].index
+	})
+	return kept
+}
@@ -0,0 +1,58 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGameMap_occupiedCities(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar\nBar north=Baz\nBaz")
+	// Units set by hand before the roster is built are found by the first scan
+	m.cities["Baz"].Aliens = []*Alien{{Number: 0, Alive: true}}
+	assert.Equal(t, []*City{m.cities["Baz"]}, m.occupiedCities())
+
+	// Landing units are enrolled, in order of the map
+	assert.NoError(t, m.PlaceAlien(&Alien{Number: 1, Alive: true}, "Foo"))
+	assert.Equal(t, []*City{m.cities["Foo"], m.cities["Baz"]}, m.occupiedCities())
+
+	// Emptied cities are dropped
+	m.cities["Baz"].leave(m.cities["Baz"].Aliens[0])
+	assert.Equal(t, []*City{m.cities["Foo"]}, m.occupiedCities())
+	assert.NoError(t, m.PlaceAlien(&Alien{Number: 2, Alive: true}, "Baz"))
+	assert.Equal(t, []*City{m.cities["Foo"], m.cities["Baz"]}, m.occupiedCities())
+}
+
+func TestGameMap_ruinedCities(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar\nBar north=Baz\nBaz")
+	m.cities["Baz"].Exists = false
+	assert.Equal(t, []*City{m.cities["Baz"]}, m.ruinedCities())
+	assert.NoError(t, m.destroyCity("Foo"))
+	assert.Equal(t, []*City{m.cities["Foo"], m.cities["Baz"]}, m.ruinedCities())
+	m.cities["Foo"].Exists = true
+	assert.Equal(t, []*City{m.cities["Baz"]}, m.ruinedCities())
+}
+
+func TestGameMap_RosterFollowsUnits(t *testing.T) {
+	m, _ := NewGridGameMap(20, 20)
+	m.SetSeed(1)
+	m.SetCityCapacity(2)
+	m.SetRebuildDelay(3)
+	aliens := make([]*Alien, 60)
+	for i := range aliens {
+		aliens[i] = m.AlienIDs().NewAlien()
+	}
+	assert.NoError(t, m.AssignAliens(aliens))
+	for i := 0; i < 50 && m.Update(); i++ {
+		occupied, ruins := []*City{}, []*City{}
+		for _, city := range m.cityList {
+			if len(city.Aliens) > 0 {
+				occupied = append(occupied, city)
+			}
+			if !city.Exists {
+				ruins = append(ruins, city)
+			}
+		}
+		assert.Equal(t, occupied, append([]*City{}, m.occupiedCities()...))
+		assert.Equal(t, ruins, append([]*City{}, m.ruinedCities()...))
+	}
+}
@@ -0,0 +1,130 @@
+package alien_invastion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scenario describes a full run : the map, aliens and defenders, rules, waves, when to stop and what to print.
+// Scenario files are YAML or JSON, fields not in the file keep values of NewScenario.
+type Scenario struct {
+	Map         string          `yaml:"map" json:"map"` // Map file, relative to the scenario file
+	Graph       bool            `yaml:"graph" json:"graph"`
+	Compact     bool            `yaml:"compact" json:"compact"` // Load the map as a compact map, for huge maps, see StreamParser.Compact
+	Workers     int             `yaml:"workers" json:"workers"` // Goroutines moving aliens in every tick, 0 for the classic engine
+	Actors      bool            `yaml:"actors" json:"actors"`   // Every alien and city is a goroutine, see GameMap.RunActors
+	Directions  string          `yaml:"directions" json:"directions"`
+	Seed        *int64          `yaml:"seed" json:"seed"` // Random if not set
+	Aliens      AliensConfig    `yaml:"aliens" json:"aliens"`
+	Defenders   DefendersConfig `yaml:"defenders" json:"defenders"`
+	Rules       Rules           `yaml:"rules" json:"rules"`
+	Spawn       []string        `yaml:"spawn" json:"spawn"` // Waves like `3@100+50x4`, see ParseWave
+	Termination Termination     `yaml:"termination" json:"termination"`
+	Output      Output          `yaml:"output" json:"output"`
+}
+
+type AliensConfig struct {
+	Count      int            `yaml:"count" json:"count"` // Aliens at start, placed ones included
+	Strength   int            `yaml:"strength" json:"strength"`
+	Health     int            `yaml:"health" json:"health"`
+	Factions   []string       `yaml:"factions" json:"factions"`
+	Strategy   string         `yaml:"strategy" json:"strategy"`
+	Stack      bool           `yaml:"stack" json:"stack"`
+	Placements []Placement    `yaml:"placements" json:"placements"` // Aliens placed into given cities, the others are placed randomly
+	At         map[int]string `yaml:"at" json:"at"`                 // Cities of aliens by their index, after the ones of placements
+	Placement  string         `yaml:"placement" json:"placement"`   // How the others are placed, see PlacementStrategyFromString
+	Names      []string       `yaml:"names" json:"names"`           // Names of units in events by their numbers, see NameList
+}
+
+// Placement puts Count aliens into City at start
+type Placement struct {
+	City  string `yaml:"city" json:"city"`
+	Count int    `yaml:"count" json:"count"`
+}
+
+type DefendersConfig struct {
+	Count    int    `yaml:"count" json:"count"` // Defenders placed randomly, besides garrisons declared by the map
+	Strategy string `yaml:"strategy" json:"strategy"`
+}
+
+type Rules struct {
+	Combat          string        `yaml:"combat" json:"combat"`
+	RetreatChance   float64       `yaml:"retreat_chance" json:"retreat_chance"`
+	SameFaction     string        `yaml:"same_faction" json:"same_faction"`
+	Capacity        int           `yaml:"capacity" json:"capacity"`
+	Gather          int           `yaml:"gather" json:"gather"`
+	RoadFailureRate float64       `yaml:"road_failure_rate" json:"road_failure_rate"`
+	RebuildDelay    int           `yaml:"rebuild_delay" json:"rebuild_delay"`
+	Fatigue         FatigueConfig `yaml:"fatigue" json:"fatigue"`
+}
+
+type FatigueConfig struct {
+	Stamina    int    `yaml:"stamina" json:"stamina"`
+	MoveCost   int    `yaml:"move_cost" json:"move_cost"`
+	Rest       int    `yaml:"rest" json:"rest"`
+	Lifespan   int    `yaml:"lifespan" json:"lifespan"`
+	Exhaustion string `yaml:"exhaustion" json:"exhaustion"`
+}
+
+// Termination ends the game once an alien goes more than MaxSteps, or after MaxTicks, 0 means no limit for both
+type Termination struct {
+	MaxSteps int `yaml:"max_steps" json:"max_steps"`
+	MaxTicks int `yaml:"max_ticks" json:"max_ticks"`
+}
+
+// Output tells what to print while running a scenario
+type Output struct {
+	Events bool `yaml:"events" json:"events"`
+	Map    bool `yaml:"map" json:"map"`
+	Stats  bool `yaml:"stats" json:"stats"`
+}
+
+// NewScenario returns a scenario with default values, the classic game without map
+func NewScenario() *Scenario {
+	return &Scenario{
+		Directions: "compass",
+		Aliens:     AliensConfig{Strength: 1, Health: 1, Strategy: "random", Placement: "uniform"},
+		Defenders:  DefendersConfig{Strategy: "guard"},
+		Rules: Rules{
+			Combat:      "mutual",
+			SameFaction: "coexist",
+			Capacity:    1,
+			Fatigue:     FatigueConfig{MoveCost: 1, Rest: 1, Exhaustion: "stay"},
+		},
+		Termination: Termination{MaxSteps: 10000},
+		Output:      Output{Events: true, Map: true},
+	}
+}
+
+// LoadScenario reads a scenario file, JSON if the file ends with .json, YAML otherwise. Unknown fields are errors.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scenario: %s", err)
+	}
+	s := NewScenario()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(s)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if s.Map == "" {
+		return nil, fmt.Errorf("%s: no map", path)
+	}
+	if !filepath.IsAbs(s.Map) {
+		s.Map = filepath.Join(filepath.Dir(path), s.Map)
+	}
+	return s, nil
+}
@@ -0,0 +1,81 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadScenario(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		validate func(t *testing.T, s *Scenario)
+	}{
+		{
+			name: "YAML",
+			path: "test_resources/scenario.yaml",
+			validate: func(t *testing.T, s *Scenario) {
+				assert.Equal(t, filepath.Join("test_resources", "sample_map.txt"), s.Map)
+				assert.Equal(t, int64(42), *s.Seed)
+				assert.Equal(t, 6, s.Aliens.Count)
+				assert.Equal(t, []string{"red", "blue"}, s.Aliens.Factions)
+				assert.Equal(t, []Placement{{City: "Akel", Count: 1}, {City: "Delmon", Count: 1}}, s.Aliens.Placements)
+				assert.Equal(t, map[int]string{2: "Rickel"}, s.Aliens.At)
+				assert.Equal(t, "spread", s.Aliens.Placement)
+				assert.Equal(t, DefendersConfig{Count: 2, Strategy: "hunt"}, s.Defenders)
+				assert.Equal(t, "strength", s.Rules.Combat)
+				assert.Equal(t, 0.1, s.Rules.RetreatChance)
+				assert.Equal(t, FatigueConfig{Stamina: 5, MoveCost: 1, Rest: 1, Exhaustion: "stay"}, s.Rules.Fatigue)
+				assert.Equal(t, []string{"2@10+10x3"}, s.Spawn)
+				assert.Equal(t, Termination{MaxSteps: 10000, MaxTicks: 200}, s.Termination)
+				assert.Equal(t, Output{Events: true, Map: true, Stats: true}, s.Output)
+			},
+		},
+		{
+			name: "JSON keeps defaults",
+			path: "test_resources/scenario.json",
+			validate: func(t *testing.T, s *Scenario) {
+				assert.Equal(t, int64(7), *s.Seed)
+				assert.Equal(t, 4, s.Aliens.Count)
+				assert.Equal(t, 1, s.Aliens.Strength)
+				assert.Equal(t, "uniform", s.Aliens.Placement)
+				assert.Equal(t, "compass", s.Directions)
+				assert.Equal(t, 1, s.Rules.Capacity)
+				assert.Equal(t, Output{Events: false, Map: true, Stats: true}, s.Output)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := LoadScenario(tt.path)
+			assert.NoError(t, err)
+			tt.validate(t, s)
+		})
+	}
+}
+
+func TestLoadScenarioErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "Unknown field", file: "unknown.yaml", content: "map: foo.txt\nalien: 3\n"},
+		{name: "Unknown JSON field", file: "unknown.json", content: `{"map": "foo.txt", "alien": 3}`},
+		{name: "No map", file: "nomap.yaml", content: "seed: 3\n"},
+		{name: "Broken", file: "broken.json", content: `{"map": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+			_, err := LoadScenario(path)
+			assert.Error(t, err)
+		})
+	}
+	_, err := LoadScenario(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package alien_invastion
+
+// Snapshot is a copy of the game at the end of a tick, safe to keep and read from any goroutine
+type Snapshot struct {
+	Tick    int
+	Map     string              // Cities left and their roads, see GameMap.DumpMap
+	Stats   Stats               // See GameMap.Stats
+	Control map[string][]string // Cities controlled by factions, see GameMap.FactionControl
+	Events  []Event             // Events happened so far, shared with the map, don't modify
+}
+
+// Snapshot copies the game at the end of current tick, safe to call from any goroutine.
+// It waits for the tick being played to end, and goes through all cities, so it is slow for huge maps.
+func (m *GameMap) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Snapshot{
+		Tick:    m.tick,
+		Map:     m.DumpMap(),
+		Stats:   m.Stats(),
+		Control: m.FactionControl(),
+		// Events are never modified once emitted, later ones are appended beyond the end of this slice
+		Events: m.events[:len(m.events):len(m.events)],
+	}
+}
+
+// View calls read with the game at the end of current tick, safe to call from any goroutine.
+// The map may be read in any way inside read, but not changed, and nothing read should be kept once read returns.
+// Ticks wait for read to return.
+func (m *GameMap) View(read func(m *GameMap)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	read(m)
+}
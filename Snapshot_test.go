@@ -0,0 +1,106 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGameMap_Snapshot(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseFile("test_resources/sample_map.txt")
+	m.SetSeed(0)
+	aliens := []*Alien{m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien(), m.AlienIDs().NewAlien()}
+	AssignFactions(aliens, []string{"red", "blue"})
+	_ = m.AssignAliens(aliens)
+
+	before := m.Snapshot()
+	assert.Equal(t, 0, before.Tick)
+	assert.Equal(t, m.DumpMap(), before.Map)
+	assert.Equal(t, m.Stats(), before.Stats)
+	assert.Equal(t, m.FactionControl(), before.Control)
+	assert.Empty(t, before.Events)
+
+	for m.Update() {
+	}
+	after := m.Snapshot()
+	assert.Equal(t, m.Tick(), after.Tick)
+	assert.Equal(t, m.DumpMap(), after.Map)
+	assert.Equal(t, m.Stats(), after.Stats)
+	assert.Equal(t, m.Events(), after.Events)
+	assert.NotEmpty(t, after.Events)
+
+	// Snapshots taken before are kept as they were
+	assert.Equal(t, 0, before.Tick)
+	assert.Empty(t, before.Events)
+	assert.NotEqual(t, before.Map, after.Map)
+}
+
+func TestGameMap_SnapshotWhilePlaying(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(m *GameMap)
+	}{
+		{name: "Classic engine", run: func(m *GameMap) {
+			for m.Update() {
+			}
+		}},
+		{name: "Workers", run: func(m *GameMap) {
+			m.SetWorkers(4)
+			for m.Update() {
+			}
+		}},
+		{name: "Actors", run: (*GameMap).RunActors},
+	}
+	for _, tt := range tests {
+		for _, grid := range gridBuilders {
+			t.Run(tt.name+"/"+grid.name, func(t *testing.T) {
+				m, _ := grid.build(20, 20)
+				m.SetSeed(0)
+				m.SetMaxTicks(200)
+				m.SetRoadFailureRate(0.01)
+				aliens := make([]*Alien, 100)
+				for i := range aliens {
+					aliens[i] = m.AlienIDs().NewAlien()
+				}
+				AssignFactions(aliens, []string{"red", "blue"})
+				_ = m.AssignAliens(aliens)
+
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					tt.run(m)
+				}()
+				var ticks []int
+				var cities int
+			watch:
+				for {
+					select {
+					case <-done:
+						break watch
+					default:
+						snapshot := m.Snapshot()
+						ticks = append(ticks, snapshot.Tick)
+						// Events are read out of the lock, while the game goes on
+						for _, event := range snapshot.Events {
+							_ = event.String()
+						}
+						m.View(func(m *GameMap) {
+							cities = m.ExistCityCount()
+							for _, city := range m.Cities() {
+								_ = len(city.Aliens)
+							}
+						})
+						assert.LessOrEqual(t, cities, 400)
+						time.Sleep(time.Millisecond)
+					}
+				}
+				for i := 1; i < len(ticks); i++ {
+					assert.LessOrEqual(t, ticks[i-1], ticks[i])
+				}
+				last := m.Snapshot()
+				assert.Equal(t, m.Tick(), last.Tick)
+				assert.Equal(t, m.DumpMap(), last.Map)
+			})
+		}
+	}
+}
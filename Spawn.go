@@ -0,0 +1,135 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Wave is a group of aliens landing at the same tick, maybe repeated every few ticks
+type Wave struct {
+	Tick   int      // Tick of the first landing, 0 lands before the first update
+	Count  int      // Aliens landing every time
+	Every  int      // Ticks between landings, only used if Times > 1
+	Times  int      // Number of landings, 1 if 0
+	Cities []string // Cities to land at, any city if empty
+}
+
+// ParseWave parses a wave like `5@0`, `3@100+100x5` (3 aliens at tick 100, then every 100 ticks, 5 times in total)
+// or `2@10:Foo|Bar` (landing at Foo or Bar only)
+func ParseWave(s string) (Wave, error) {
+	var wave Wave
+	schedule, cities, found := strings.Cut(strings.TrimSpace(s), ":")
+	if found {
+		for _, city := range strings.Split(cities, "|") {
+			if city = strings.TrimSpace(city); city != "" {
+				wave.Cities = append(wave.Cities, city)
+			}
+		}
+	}
+	count, rest, found := strings.Cut(schedule, "@")
+	if !found {
+		return wave, fmt.Errorf("%s: wave should look like COUNT@TICK", s)
+	}
+	tick, repeat, found := strings.Cut(rest, "+")
+	numbers := []*int{&wave.Count, &wave.Tick}
+	texts := []string{count, tick}
+	if found {
+		every, times, found := strings.Cut(repeat, "x")
+		if !found {
+			return wave, fmt.Errorf("%s: repeated wave should look like COUNT@TICK+EVERYxTIMES", s)
+		}
+		numbers = append(numbers, &wave.Every, &wave.Times)
+		texts = append(texts, every, times)
+	}
+	for i, text := range texts {
+		number, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil || number < 0 {
+			return wave, fmt.Errorf("%s: invalid number %s", s, text)
+		}
+		*numbers[i] = number
+	}
+	if wave.Times > 1 && wave.Every < 1 {
+		return wave, fmt.Errorf("%s: repeated wave needs at least 1 tick between landings", s)
+	}
+	return wave, nil
+}
+
+// landsAt tells if the wave lands at given tick
+func (w Wave) landsAt(tick int) bool {
+	if tick < w.Tick {
+		return false
+	}
+	if tick == w.Tick {
+		return true
+	}
+	return w.Times > 1 && (tick-w.Tick)%w.Every == 0 && (tick-w.Tick)/w.Every < w.Times
+}
+
+// lastTick returns tick of the last landing
+func (w Wave) lastTick() int {
+	if w.Times > 1 {
+		return w.Tick + w.Every*(w.Times-1)
+	}
+	return w.Tick
+}
+
+// SetSpawnSchedule lets waves of aliens land while the game goes on. newAlien creates every alien landing,
+// numbered by AlienIDs of the map if nil.
+// Aliens land like AssignAliens places them, a city can't be landed at if it is destroyed, or occupied without stacked placement.
+// Error if a wave lands at an unknown city.
+func (m *GameMap) SetSpawnSchedule(waves []Wave, newAlien func() *Alien) error {
+	for _, wave := range waves {
+		for _, name := range wave.Cities {
+			if m.lookup(name) == nil {
+				return fmt.Errorf("city %s to land at doesn't exist", name)
+			}
+		}
+	}
+	if newAlien == nil {
+		newAlien = m.AlienIDs().NewAlien
+	}
+	m.waves = waves
+	m.newAlien = newAlien
+	return nil
+}
+
+// spawn lands waves of current tick. Aliens have nowhere to land are not created.
+func (m *GameMap) spawn() {
+	for _, wave := range m.waves {
+		if !wave.landsAt(m.tick) {
+			continue
+		}
+		ids := m.cityIDs()
+		if len(wave.Cities) > 0 {
+			ids = nil
+			listed := make(map[string]bool)
+			for _, name := range wave.Cities {
+				if !listed[name] {
+					ids = append(ids, int32(m.lookup(name).index))
+					listed[name] = true
+				}
+			}
+		}
+		pool := m.newLandingPool(ids)
+		for i := 0; i < wave.Count; i++ {
+			index := pool.pick(Invaders)
+			if index < 0 {
+				break
+			}
+			alien := m.newAlien()
+			city := pool.take(index, alien)
+			m.emit(Event{Type: AlienSpawned, City: city.Name, Aliens: []int{alien.Number}})
+		}
+	}
+}
+
+// wavesPending tells if there is a wave to land after current tick
+func (m *GameMap) wavesPending() bool {
+	for _, wave := range m.waves {
+		if wave.lastTick() >= m.tick {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseWave(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Wave
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "Once",
+			s:       "5@0",
+			want:    Wave{Count: 5},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "Repeated",
+			s:       "3@100+100x5",
+			want:    Wave{Tick: 100, Count: 3, Every: 100, Times: 5},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "Designated cities",
+			s:       "2@10:Foo|Bar baz",
+			want:    Wave{Tick: 10, Count: 2, Cities: []string{"Foo", "Bar baz"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "No tick",
+			s:       "5",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "No times",
+			s:       "3@100+100",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "Repeated without interval",
+			s:       "3@100+0x5",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "Negative count",
+			s:       "-3@100",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWave(tt.s)
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWave_landsAt(t *testing.T) {
+	once := Wave{Tick: 3, Count: 1}
+	repeated := Wave{Tick: 10, Count: 1, Every: 5, Times: 3}
+	tests := []struct {
+		name string
+		wave Wave
+		tick int
+		want bool
+	}{
+		{name: "Once before", wave: once, tick: 2, want: false},
+		{name: "Once", wave: once, tick: 3, want: true},
+		{name: "Once after", wave: once, tick: 4, want: false},
+		{name: "First landing", wave: repeated, tick: 10, want: true},
+		{name: "Between landings", wave: repeated, tick: 12, want: false},
+		{name: "Last landing", wave: repeated, tick: 20, want: true},
+		{name: "No more landing", wave: repeated, tick: 25, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.wave.landsAt(tt.tick))
+		})
+	}
+}
+
+func TestGameMap_SetSpawnSchedule(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar")
+	assert.Error(t, m.SetSpawnSchedule([]Wave{{Count: 1, Cities: []string{"Baz"}}}, nil))
+	assert.NoError(t, m.SetSpawnSchedule([]Wave{{Count: 1, Cities: []string{"Bar"}}}, nil))
+}
+
+func TestGameMap_UpdateWithWaves(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar\nBaz")
+	m.SetCityCapacity(0)
+	var spawned []*Alien
+	err := m.SetSpawnSchedule([]Wave{{Tick: 2, Count: 1, Every: 3, Times: 2, Cities: []string{"Baz"}}}, func() *Alien {
+		alien := &Alien{Number: 100 + len(spawned), Alive: true, Faction: "red"}
+		spawned = append(spawned, alien)
+		return alien
+	})
+	assert.NoError(t, err)
+
+	// No alien yet, but waiting for waves
+	assert.True(t, m.Update())
+	assert.True(t, m.Update())
+	assert.Empty(t, spawned)
+
+	// First wave lands before third update
+	assert.True(t, m.Update())
+	assert.Equal(t, 1, len(spawned))
+	assert.Equal(t, spawned, m.cities["Baz"].Aliens)
+	assert.Equal(t, []Event{{Tick: 2, Type: AlienSpawned, City: "Baz", Aliens: []int{100}}}, m.Events())
+
+	// Second wave can't land, Baz is occupied and stacked placement is not allowed
+	m.Update()
+	m.Update()
+	m.Update()
+	assert.Equal(t, 1, len(spawned))
+	assert.False(t, m.wavesPending())
+}
+
+func TestGameMap_SpawnAtListedCitiesOnce(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar")
+	var spawned []*Alien
+	err := m.SetSpawnSchedule([]Wave{{Count: 3, Cities: []string{"Foo", "Foo"}}}, func() *Alien {
+		alien := &Alien{Number: len(spawned), Alive: true}
+		spawned = append(spawned, alien)
+		return alien
+	})
+	assert.NoError(t, err)
+	m.spawn()
+	assert.Equal(t, 1, len(spawned))
+	assert.Equal(t, spawned, m.cities["Foo"].Aliens)
+}
@@ -0,0 +1,45 @@
+package alien_invastion
+
+import "fmt"
+
+// Stats sums up the game so far
+type Stats struct {
+	Ticks           int
+	CitiesLeft      int
+	CitiesDestroyed int
+	CitiesRebuilt   int
+	RoadsDestroyed  int
+	Invaders        int // Invaders still alive
+	Defenders       int // Defenders still alive
+	AliensSpawned   int
+	AliensExhausted int
+	AliensExpired   int
+}
+
+// Stats counts events happened so far, and units still alive
+func (m *GameMap) Stats() Stats {
+	stats := Stats{Ticks: m.tick, CitiesLeft: m.ExistCityCount()}
+	stats.Invaders, stats.Defenders = m.units()
+	for _, e := range m.events {
+		switch e.Type {
+		case CityDestroyed:
+			stats.CitiesDestroyed++
+		case CityRebuilt:
+			stats.CitiesRebuilt++
+		case RoadDestroyed:
+			stats.RoadsDestroyed++
+		case AlienSpawned:
+			stats.AliensSpawned++
+		case AlienExhausted:
+			stats.AliensExhausted++
+		case AlienExpired:
+			stats.AliensExpired++
+		}
+	}
+	return stats
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("ticks=%d cities-left=%d cities-destroyed=%d cities-rebuilt=%d roads-destroyed=%d invaders=%d defenders=%d aliens-spawned=%d aliens-exhausted=%d aliens-expired=%d",
+		s.Ticks, s.CitiesLeft, s.CitiesDestroyed, s.CitiesRebuilt, s.RoadsDestroyed, s.Invaders, s.Defenders, s.AliensSpawned, s.AliensExhausted, s.AliensExpired)
+}
@@ -0,0 +1,22 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGameMap_Stats(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar\nBar north=Baz\nBaz north=Bee")
+	m.cities["Foo"].Aliens = []*Alien{{Alive: true}}
+	m.cities["Bar"].Aliens = []*Alien{{Alive: true, Side: Defenders}}
+	m.tick = 9
+	m.emit(Event{Type: CityDestroyed, City: "Bee"})
+	m.emit(Event{Type: AlienSpawned, City: "Foo", Aliens: []int{1}})
+	m.emit(Event{Type: AlienExhausted, City: "Baz", Aliens: []int{2}})
+	m.emit(Event{Type: AlienExpired, City: "Baz", Aliens: []int{3}})
+	_ = m.destroyCity("Bee")
+
+	want := Stats{Ticks: 9, CitiesLeft: 3, CitiesDestroyed: 1, Invaders: 1, Defenders: 1, AliensSpawned: 1, AliensExhausted: 1, AliensExpired: 1}
+	assert.Equal(t, want, m.Stats())
+	assert.Equal(t, "ticks=9 cities-left=3 cities-destroyed=1 cities-rebuilt=0 roads-destroyed=0 invaders=1 defenders=1 aliens-spawned=1 aliens-exhausted=1 aliens-expired=1", want.String())
+}
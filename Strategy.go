@@ -0,0 +1,80 @@
+package alien_invastion
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// MovementStrategy decides where a unit goes in every tick. exits are roads leading to cities still exist, never empty.
+// move is false if the unit stays in the city.
+type MovementStrategy interface {
+	Next(rng *rand.Rand, unit *Alien, from *City, exits []Exit) (exit Exit, move bool)
+}
+
+// RandomWalk is the classic rule : take a random road, always
+type RandomWalk struct {
+}
+
+func (s RandomWalk) Next(rng *rand.Rand, unit *Alien, from *City, exits []Exit) (Exit, bool) {
+	return exits[rng.Intn(len(exits))], true
+}
+
+// Guard never leaves the city
+type Guard struct {
+}
+
+func (s Guard) Next(rng *rand.Rand, unit *Alien, from *City, exits []Exit) (Exit, bool) {
+	return Exit{}, false
+}
+
+// Hunt goes to a neighbor city holding a rival, or stays if there is none
+type Hunt struct {
+}
+
+func (s Hunt) Next(rng *rand.Rand, unit *Alien, from *City, exits []Exit) (Exit, bool) {
+	var targets []Exit
+	for _, exit := range exits {
+		for _, other := range exit.To.Aliens {
+			if other.Alive && unit.IsRival(other) {
+				targets = append(targets, exit)
+				break
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return Exit{}, false
+	}
+	return targets[rng.Intn(len(targets))], true
+}
+
+// Player lets something outside the engine, like a human on the command line, decide where the unit goes
+// Decide is always called from the goroutine calling Update, even with workers, and may read the whole map.
+type Player struct {
+	Decide func(unit *Alien, from *City, exits []Exit) (exit Exit, move bool)
+}
+
+func (s Player) Next(rng *rand.Rand, unit *Alien, from *City, exits []Exit) (Exit, bool) {
+	return s.Decide(unit, from, exits)
+}
+
+// MovementStrategyFromString returns one of the built-in strategies by name : random, guard or hunt
+func MovementStrategyFromString(name string) (MovementStrategy, error) {
+	switch name {
+	case "random":
+		return RandomWalk{}, nil
+	case "guard":
+		return Guard{}, nil
+	case "hunt":
+		return Hunt{}, nil
+	default:
+		return nil, fmt.Errorf("unknown movement strategy %s", name)
+	}
+}
+
+// strategy returns movement strategy of the unit, RandomWalk if not specified
+func (a *Alien) strategy() MovementStrategy {
+	if a.Strategy == nil {
+		return RandomWalk{}
+	}
+	return a.Strategy
+}
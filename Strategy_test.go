@@ -0,0 +1,103 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestMovementStrategyFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    MovementStrategy
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "random", want: RandomWalk{}, wantErr: assert.NoError},
+		{name: "guard", want: Guard{}, wantErr: assert.NoError},
+		{name: "hunt", want: Hunt{}, wantErr: assert.NoError},
+		{name: "teleport", want: nil, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MovementStrategyFromString(tt.name)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMovementStrategy_Next(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar south=Baz")
+	m.cities["Baz"].Aliens = []*Alien{{Number: 1, Alive: true}}
+	unit := &Alien{Number: 0, Alive: true, Side: Defenders}
+	foo := m.cities["Foo"]
+	rng := rand.New(rand.NewSource(0))
+
+	tests := []struct {
+		name     string
+		strategy MovementStrategy
+		wantMove bool
+		wantTo   []string
+	}{
+		{
+			name:     "Random walk goes anywhere",
+			strategy: RandomWalk{},
+			wantMove: true,
+			wantTo:   []string{"Bar", "Baz"},
+		},
+		{
+			name:     "Guard stays",
+			strategy: Guard{},
+			wantMove: false,
+		},
+		{
+			name:     "Hunt goes to the rival",
+			strategy: Hunt{},
+			wantMove: true,
+			wantTo:   []string{"Baz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				exit, move := tt.strategy.Next(rng, unit, foo, foo.Exits())
+				assert.Equal(t, tt.wantMove, move)
+				if move {
+					assert.Contains(t, tt.wantTo, exit.To.Name)
+				}
+			}
+		})
+	}
+
+	// Nobody to hunt
+	m.cities["Baz"].Aliens = nil
+	_, move := Hunt{}.Next(rng, unit, foo, foo.Exits())
+	assert.False(t, move)
+}
+
+func TestAlien_MoveWithStrategy(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar")
+	guard := &Alien{Number: 0, Alive: true, Strategy: Guard{}}
+	m.cities["Foo"].Aliens = []*Alien{guard}
+	to, steps := guard.Move(m.cities["Foo"])
+	assert.Equal(t, m.cities["Foo"], to)
+	assert.Equal(t, 1, steps)
+	assert.Equal(t, []*Alien{guard}, m.cities["Foo"].Aliens)
+}
+
+func TestPlayer_Next(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo north=Bar south=Baz")
+	unit := &Alien{Number: 0, Alive: true}
+	var asked []Exit
+	player := Player{Decide: func(u *Alien, from *City, exits []Exit) (Exit, bool) {
+		assert.Equal(t, unit, u)
+		asked = exits
+		return exits[1], true
+	}}
+	unit.Strategy = player
+	m.cities["Foo"].Aliens = []*Alien{unit}
+	to, _ := unit.Move(m.cities["Foo"])
+	assert.Equal(t, 2, len(asked))
+	assert.Equal(t, asked[1].To, to)
+	assert.Equal(t, []*Alien{unit}, to.Aliens)
+}
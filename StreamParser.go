@@ -4,10 +4,18 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
 type StreamParser struct {
+	// Directions is the direction set of parsed maps, compass if not specified
+	Directions *DirectionSet
+	// Graph parses maps as graph maps, see NewGraphGameMap
+	Graph bool
+	// Compact turns parsed maps into compact maps, see GameMap.Compact. The map is parsed into a regular map first,
+	// so loading takes as much memory as a regular map, the compact map only saves it once the regular one is collected.
+	Compact bool
 }
 
 func (s *StreamParser) ParseFile(filepath string) (ret *GameMap, errors []error) {
@@ -34,7 +42,11 @@ func (s *StreamParser) ParseString(str string) (ret *GameMap, errors []error) {
 }
 
 func (s *StreamParser) parseScannerResult(ret *GameMap, scanner *bufio.Scanner, errors []error) (*GameMap, []error) {
-	ret = NewGameMap()
+	if s.Graph {
+		ret = NewGraphGameMap()
+	} else {
+		ret = NewGameMapWithDirections(s.Directions)
+	}
 	for scanner.Scan() {
 		line := scanner.Text()
 		errs := s.parseSingleLine(line, ret)
@@ -42,27 +54,72 @@ func (s *StreamParser) parseScannerResult(ret *GameMap, scanner *bufio.Scanner,
 			errors = append(errors, errs...)
 		}
 	}
+	if s.Compact {
+		ret = ret.Compact()
+	}
 	return ret, errors
 }
 
 func (s *StreamParser) parseSingleLine(line string, ret *GameMap) (errors []error) {
+	if ret.IsGraph() {
+		return s.parseGraphLine(line, ret)
+	}
+	if strings.Contains(line, "->") {
+		return append(errors, fmt.Errorf("%s: graph roads are only allowed in graph maps", line))
+	}
 	// Line will looks like :
 	// Foo north=Bar west=Baz south=Qu-ux
-	// Bar south=Foo west=Bee
+	// Bar hp=3 defense=1 capacity=2 south=Foo west=Bee:3 east=>Baz
 	elems := strings.Split(line, " ")
+	var city *City
 	for i, elem := range elems {
 		if i == 0 {
-			ret.UpsertCity(strings.Trim(elem, " "))
+			city = ret.UpsertCity(strings.Trim(elem, " "))
 			continue
 		}
 
-		if strings.Contains(elem, "=") {
+		if isAttribute, err := parseCityAttribute(city, elem); isAttribute {
+			if err != nil {
+				errors = append(errors, err)
+			}
+		} else if strings.Contains(elem, "=>") {
+			dirCityPair := strings.Split(elem, "=>")
+			if len(dirCityPair) != 2 || strings.Contains(dirCityPair[1], "=") {
+				continue
+			}
+			direction := DirectionFromString(dirCityPair[0])
+			if direction == Invalid {
+				errors = append(errors, fmt.Errorf("%s: unknown direction %s", elems[0], dirCityPair[0]))
+				continue
+			}
+			cityName, length, err := splitRoadLength(dirCityPair[1])
+			if err == nil {
+				err = ret.UpdateCityWithOneWayRoad(elems[0], direction, cityName)
+			}
+			if err == nil && length > 0 {
+				err = ret.SetRoadLength(elems[0], direction, cityName, length)
+			}
+			if err != nil {
+				errors = append(errors, err)
+			}
+		} else if strings.Contains(elem, "=") {
 			//Regex might be another way but it's overkill
 			dirCityPair := strings.Split(elem, "=")
 			if len(dirCityPair) != 2 {
 				continue
 			}
-			err := ret.UpdateCityWithNeighborhood(elems[0], DirectionFromString(dirCityPair[0]), strings.Trim(dirCityPair[1], " "))
+			direction := DirectionFromString(dirCityPair[0])
+			if direction == Invalid {
+				errors = append(errors, fmt.Errorf("%s: unknown direction %s", elems[0], dirCityPair[0]))
+				continue
+			}
+			cityName, length, err := splitRoadLength(dirCityPair[1])
+			if err == nil {
+				err = ret.UpdateCityWithNeighborhood(elems[0], direction, cityName)
+			}
+			if err == nil && length > 0 {
+				err = ret.SetRoadLength(elems[0], direction, cityName, length)
+			}
 			if err != nil {
 				errors = append(errors, err)
 			}
@@ -70,3 +127,114 @@ func (s *StreamParser) parseSingleLine(line string, ret *GameMap) (errors []erro
 	}
 	return
 }
+
+func (s *StreamParser) parseGraphLine(line string, ret *GameMap) (errors []error) {
+	// Line will looks like :
+	// Foo -> Bar, Baz:3, highway=Qu-ux
+	// Foo => Bee
+	// Bar hp=3 defense=1
+	oneWay := false
+	elems := strings.SplitN(line, "->", 2)
+	if len(elems) < 2 && strings.Contains(line, "=>") {
+		elems = strings.SplitN(line, "=>", 2)
+		oneWay = true
+	}
+	// City names may contain spaces, attributes are at the end
+	words := strings.Fields(elems[0])
+	var attributes []string
+	for len(words) > 1 && strings.Contains(words[len(words)-1], "=") {
+		attributes = append(attributes, words[len(words)-1])
+		words = words[:len(words)-1]
+	}
+	name := strings.Join(words, " ")
+	if name == "" {
+		return
+	}
+	city := ret.UpsertCity(name)
+	for _, attribute := range attributes {
+		if isAttribute, err := parseCityAttribute(city, attribute); !isAttribute {
+			errors = append(errors, fmt.Errorf("%s: unknown attribute %s", name, attribute))
+		} else if err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if len(elems) < 2 {
+		return
+	}
+
+	for _, elem := range strings.Split(elems[1], ",") {
+		elem = strings.Trim(elem, " ")
+		if elem == "" {
+			continue
+		}
+		roadName, cityName := "", elem
+		if strings.Contains(elem, "=>") {
+			errors = append(errors, fmt.Errorf("%s: one-way roads are declared in their own line, like `%s => %s`", name, name, strings.Trim(strings.SplitN(elem, "=>", 2)[1], " ")))
+			continue
+		}
+		if strings.Contains(elem, "=") {
+			roadCityPair := strings.Split(elem, "=")
+			if len(roadCityPair) != 2 {
+				continue
+			}
+			roadName, cityName = strings.Trim(roadCityPair[0], " "), strings.Trim(roadCityPair[1], " ")
+		}
+		cityName, length, err := splitRoadLength(cityName)
+		if err == nil && oneWay {
+			err = ret.ConnectCitiesOneWay(name, roadName, cityName)
+		} else if err == nil {
+			err = ret.ConnectCities(name, roadName, cityName)
+		}
+		if err == nil && length > 0 {
+			err = ret.SetRoadLength(name, Invalid, cityName, length)
+		}
+		if err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return
+}
+
+// splitRoadLength splits `Bar:3` into city name and road length, length is 0 if not specified
+func splitRoadLength(elem string) (string, int, error) {
+	cityName, lengthStr, found := strings.Cut(strings.Trim(elem, " "), ":")
+	if !found {
+		return cityName, 0, nil
+	}
+	length, err := strconv.Atoi(lengthStr)
+	if err != nil || length < 1 {
+		return cityName, 0, fmt.Errorf("%s: invalid road length %s", cityName, lengthStr)
+	}
+	return cityName, length, nil
+}
+
+// parseCityAttribute parses `hp=3`, `defense=1`, `capacity=2` or `defenders=1` into the city. isAttribute is false if elem is not a city attribute.
+func parseCityAttribute(city *City, elem string) (isAttribute bool, err error) {
+	key, value, found := strings.Cut(elem, "=")
+	if !found {
+		return false, nil
+	}
+	var field *int
+	var min int
+	switch strings.ToLower(key) {
+	case "hp":
+		field, min = &city.HitPoints, 1
+	case "defense":
+		field, min = &city.Defense, 0
+	case "capacity":
+		field, min = &city.Capacity, 1
+	case "defenders":
+		field, min = &city.Garrison, 0
+	default:
+		return false, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min {
+		return true, fmt.Errorf("%s: invalid %s %s", city.Name, key, value)
+	}
+	*field = number
+	if field == &city.HitPoints {
+		city.hitPoints = number
+	}
+	return true, nil
+}
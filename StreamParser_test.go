@@ -100,6 +100,14 @@ func TestStreamParser_ParseString(t *testing.T) {
 			wantSize:      5,
 			wantErrorSize: 1,
 		},
+		{
+			name: "8-way map with compass parser",
+			args: args{
+				str: "Foo north=Bar northeast=Baz\nBaz southwest=Foo",
+			},
+			wantSize:      3,
+			wantErrorSize: 2,
+		},
 		{
 			name: "Empty string",
 			args: args{
@@ -125,6 +133,180 @@ func TestStreamParser_ParseString(t *testing.T) {
 	}
 }
 
+func TestStreamParser_ParseStringWithDirections(t *testing.T) {
+	s := &StreamParser{Directions: EightWayDirections}
+	gotRet, gotErrors := s.ParseString("Foo north=Bar northeast=Baz\nBaz southwest=Foo")
+	assert.Empty(t, gotErrors)
+	assert.Equal(t, EightWayDirections, gotRet.Directions())
+	assert.Equal(t, "Foo", gotRet.cities["Baz"].Neighborhoods[SouthWest].Name)
+}
+
+func TestStreamParser_ParseGraphFile(t *testing.T) {
+	s := &StreamParser{Graph: true}
+	gotRet, gotErrors := s.ParseFile("test_resources/graph_map.txt")
+	assert.Empty(t, gotErrors)
+	assert.True(t, gotRet.IsGraph())
+	assert.Equal(t, 9, len(gotRet.cities))
+	assert.Equal(t, 4, len(gotRet.cities["Akel"].Exits()))
+	assert.Equal(t, "bridge", gotRet.cities["Springfield"].Exits()[0].Label())
+}
+
+func TestStreamParser_parseGraphLine(t *testing.T) {
+	tests := []struct {
+		name          string
+		line          string
+		wantSize      int
+		wantErrorSize int
+	}{
+		{
+			name:     "Happy Path",
+			line:     "Foo -> Bar, Baz, highway=Qu-ux",
+			wantSize: 4,
+		},
+		{
+			name:     "City with spaces and no roads",
+			line:     "New Foo",
+			wantSize: 1,
+		},
+		{
+			name:     "Empty line",
+			line:     "",
+			wantSize: 0,
+		},
+		{
+			name:     "Invalid pair and empty entries",
+			line:     "Foo -> Bar,, a=b=c",
+			wantSize: 2,
+		},
+		{
+			name:     "One-way line",
+			line:     "Foo => Bar, river=Baz",
+			wantSize: 3,
+		},
+		{
+			name:          "One-way entry in two-way line",
+			line:          "Foo -> Bar, river=>Baz",
+			wantSize:      2,
+			wantErrorSize: 1,
+		},
+		{
+			name:     "Road lengths",
+			line:     "Foo -> Bar:3, highway=Baz:10",
+			wantSize: 3,
+		},
+		{
+			name:          "Invalid road length",
+			line:          "Foo -> Bar:0, Baz:x",
+			wantSize:      1,
+			wantErrorSize: 2,
+		},
+		{
+			name:     "City with spaces and attributes",
+			line:     "New Foo hp=3 defense=1 -> Bar",
+			wantSize: 2,
+		},
+		{
+			name:          "Unknown attribute",
+			line:          "Foo size=3",
+			wantSize:      1,
+			wantErrorSize: 1,
+		},
+		{
+			name:          "Road to itself",
+			line:          "Foo -> Bar, Foo",
+			wantSize:      2,
+			wantErrorSize: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StreamParser{}
+			gameMap := NewGraphGameMap()
+			gotErrors := s.parseSingleLine(tt.line, gameMap)
+			assert.Equalf(t, tt.wantErrorSize, len(gotErrors), "ParseLine(%v)", tt.line)
+			assert.Equalf(t, tt.wantSize, len(gameMap.cities), "ParseLine(%v)", tt.line)
+		})
+	}
+}
+
+func Test_parseCityAttribute(t *testing.T) {
+	tests := []struct {
+		name          string
+		elem          string
+		wantAttribute bool
+		wantErr       assert.ErrorAssertionFunc
+		wantHitPoints int
+		wantDefense   int
+		wantCapacity  int
+		wantGarrison  int
+	}{
+		{
+			name:          "Hit points",
+			elem:          "hp=4",
+			wantAttribute: true,
+			wantErr:       assert.NoError,
+			wantHitPoints: 4,
+		},
+		{
+			name:          "Defense",
+			elem:          "Defense=2",
+			wantAttribute: true,
+			wantErr:       assert.NoError,
+			wantHitPoints: 1,
+			wantDefense:   2,
+		},
+		{
+			name:          "Capacity",
+			elem:          "capacity=3",
+			wantAttribute: true,
+			wantErr:       assert.NoError,
+			wantHitPoints: 1,
+			wantCapacity:  3,
+		},
+		{
+			name:          "Garrison",
+			elem:          "defenders=2",
+			wantAttribute: true,
+			wantErr:       assert.NoError,
+			wantHitPoints: 1,
+			wantGarrison:  2,
+		},
+		{
+			name:          "Zero capacity",
+			elem:          "capacity=0",
+			wantAttribute: true,
+			wantErr:       assert.Error,
+			wantHitPoints: 1,
+		},
+		{
+			name:          "Direction is not an attribute",
+			elem:          "north=Bar",
+			wantAttribute: false,
+			wantErr:       assert.NoError,
+			wantHitPoints: 1,
+		},
+		{
+			name:          "Negative defense",
+			elem:          "defense=-1",
+			wantAttribute: true,
+			wantErr:       assert.Error,
+			wantHitPoints: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			city := newCity("Foo")
+			gotAttribute, err := parseCityAttribute(city, tt.elem)
+			assert.Equal(t, tt.wantAttribute, gotAttribute)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantHitPoints, city.HitPoints)
+			assert.Equal(t, tt.wantDefense, city.Defense)
+			assert.Equal(t, tt.wantCapacity, city.Capacity)
+			assert.Equal(t, tt.wantGarrison, city.Garrison)
+		})
+	}
+}
+
 func TestStreamParser_parseSingleLine(t *testing.T) {
 	type args struct {
 		line    string
@@ -163,6 +345,87 @@ func TestStreamParser_parseSingleLine(t *testing.T) {
 			wantSize:      4,
 			wantErrorSize: 0,
 		},
+		{
+			name: "Diagonal in compass map",
+			args: args{
+				line:    "Foo north=Bar northeast=Baz",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      2,
+			wantErrorSize: 1,
+		},
+		{
+			name: "Unknown direction",
+			args: args{
+				line:    "Foo north=Bar sideways=Baz",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      2,
+			wantErrorSize: 1,
+		},
+		{
+			name: "Diagonal and vertical in 8way3d map",
+			args: args{
+				line:    "Foo north=Bar northeast=Baz down=Cellar",
+				gameMap: NewGameMapWithDirections(EightWayVerticalDirections),
+			},
+			wantSize:      4,
+			wantErrorSize: 0,
+		},
+		{
+			name: "One-way roads",
+			args: args{
+				line:    "Foo north=>Bar west=Baz south=>Qu-ux east=>a=b",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      4,
+			wantErrorSize: 0,
+		},
+		{
+			name: "One-way road with unknown direction",
+			args: args{
+				line:    "Foo up=>Bar",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      1,
+			wantErrorSize: 1,
+		},
+		{
+			name: "Road lengths",
+			args: args{
+				line:    "Foo north=Bar:3 west=>Baz:2 south=Qu-ux:-1",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      3,
+			wantErrorSize: 1,
+		},
+		{
+			name: "City attributes",
+			args: args{
+				line:    "Foo hp=3 defense=0 north=Bar",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      2,
+			wantErrorSize: 0,
+		},
+		{
+			name: "Invalid city attributes",
+			args: args{
+				line:    "Foo hp=0 defense=x north=Bar",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      2,
+			wantErrorSize: 2,
+		},
+		{
+			name: "Graph road in compass map",
+			args: args{
+				line:    "Foo -> Bar, Baz",
+				gameMap: NewGameMap(),
+			},
+			wantSize:      0,
+			wantErrorSize: 1,
+		},
 		{
 			name: "Conflict path",
 			args: args{
@@ -0,0 +1,215 @@
+package alien_invastion
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sweep plays a scenario in every configuration of maps, movement strategies and alien counts, Runs games each, see RunBatch
+type Sweep struct {
+	Maps       []string
+	Strategies []string // See MovementStrategyFromString
+	Aliens     []int
+	Runs       int
+	Parallel   int // Games played at the same time
+}
+
+// SweepConfig is a configuration of a sweep
+type SweepConfig struct {
+	Map      string
+	Strategy string
+	Aliens   int
+}
+
+func (c SweepConfig) String() string {
+	return fmt.Sprintf("map=%s strategy=%s aliens=%d", c.Map, c.Strategy, c.Aliens)
+}
+
+// SweepRow sums up games of a configuration
+type SweepRow struct {
+	SweepConfig
+	Runs            int
+	Seed            int64   // Seed of the first game, game i is seeded with Seed plus i
+	Survival        float64 // Share of cities surviving, over all games
+	CitiesDestroyed Distribution
+	Ticks           Distribution
+	CasualtyRate    Distribution
+}
+
+// SweepColumns are columns of a sweep table, one row per configuration
+var SweepColumns = []string{
+	"map", "strategy", "aliens", "runs", "seed", "survival",
+	"cities_destroyed_mean", "cities_destroyed_p50", "cities_destroyed_p90",
+	"ticks_mean", "ticks_p50", "ticks_p90",
+	"casualty_rate_mean", "casualty_rate_p50", "casualty_rate_p90",
+}
+
+// Record returns the row as a record of a sweep table, see SweepColumns
+func (r SweepRow) Record() []string {
+	return []string{
+		r.Map, r.Strategy, strconv.Itoa(r.Aliens), strconv.Itoa(r.Runs), strconv.FormatInt(r.Seed, 10), formatFloat(r.Survival),
+		formatFloat(r.CitiesDestroyed.Mean), formatFloat(r.CitiesDestroyed.P50), formatFloat(r.CitiesDestroyed.P90),
+		formatFloat(r.Ticks.Mean), formatFloat(r.Ticks.P50), formatFloat(r.Ticks.P90),
+		formatFloat(r.CasualtyRate.Mean), formatFloat(r.CasualtyRate.P50), formatFloat(r.CasualtyRate.P90),
+	}
+}
+
+// SweepProgress tells what a sweep table written before has done already, to resume the sweep
+type SweepProgress struct {
+	Done map[SweepConfig]bool
+	Runs int    // Games of every configuration done, 0 if none is done
+	Seed *int64 // Seed configurations done used, nil if none is done
+	Size int64  // Bytes of the table up to its last complete row, a sweep interrupted while writing may leave part of a row after
+}
+
+// ReadSweepProgress reads a sweep table written before, error if it is not a sweep table or its rows don't agree.
+// A last row not ending with a new line was cut while writing it, it is ignored as if not done.
+func ReadSweepProgress(r io.Reader) (SweepProgress, error) {
+	progress := SweepProgress{Done: make(map[SweepConfig]bool)}
+	table, err := io.ReadAll(r)
+	if err != nil {
+		return progress, err
+	}
+	progress.Size = int64(bytes.LastIndexByte(table, '\n') + 1)
+	records, err := csv.NewReader(bytes.NewReader(table[:progress.Size])).ReadAll()
+	if err != nil {
+		return progress, err
+	}
+	if len(records) == 0 {
+		return progress, nil
+	}
+	if strings.Join(records[0], ",") != strings.Join(SweepColumns, ",") {
+		return progress, fmt.Errorf("not a sweep table, columns should be %s", strings.Join(SweepColumns, ","))
+	}
+	for i, record := range records[1:] {
+		aliens, err1 := strconv.Atoi(record[2])
+		runs, err2 := strconv.Atoi(record[3])
+		seed, err3 := strconv.ParseInt(record[4], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return progress, fmt.Errorf("row %d: invalid aliens, runs or seed", i+1)
+		}
+		if progress.Seed != nil && (seed != *progress.Seed || runs != progress.Runs) {
+			return progress, fmt.Errorf("row %d: played %d games with seed %d, but others %d games with seed %d", i+1, runs, seed, progress.Runs, *progress.Seed)
+		}
+		progress.Seed, progress.Runs = &seed, runs
+		progress.Done[SweepConfig{Map: record[0], Strategy: record[1], Aliens: aliens}] = true
+	}
+	return progress, nil
+}
+
+// Configs lists configurations of the sweep, by map, then strategy, then alien count
+func (w Sweep) Configs() []SweepConfig {
+	var configs []SweepConfig
+	for _, m := range w.Maps {
+		for _, strategy := range w.Strategies {
+			for _, aliens := range w.Aliens {
+				configs = append(configs, SweepConfig{Map: m, Strategy: strategy, Aliens: aliens})
+			}
+		}
+	}
+	return configs
+}
+
+// Run plays base in every configuration not done yet by progress, and calls emit with every configuration summed up,
+// with the number of configurations done so far out of all. All configurations are seeded with the seed of base,
+// the seed of progress if not set, or a random seed. Stops at the first error, of a game or of emit.
+func (w Sweep) Run(base *Scenario, progress SweepProgress, emit func(row SweepRow, done, total int) error) error {
+	seed := time.Now().UnixNano()
+	switch {
+	case progress.Seed != nil && base.Seed != nil && *progress.Seed != *base.Seed:
+		return fmt.Errorf("sweep to resume used seed %d, not %d", *progress.Seed, *base.Seed)
+	case progress.Seed != nil && progress.Runs != w.Runs:
+		return fmt.Errorf("sweep to resume played %d games of every configuration, not %d", progress.Runs, w.Runs)
+	case base.Seed != nil:
+		seed = *base.Seed
+	case progress.Seed != nil:
+		seed = *progress.Seed
+	}
+
+	configs := w.Configs()
+	done := 0
+	for _, config := range configs {
+		if progress.Done[config] {
+			done++
+		}
+	}
+	for _, config := range configs {
+		if progress.Done[config] {
+			continue
+		}
+		scenario := *base
+		scenario.Map = config.Map
+		scenario.Aliens.Strategy = config.Strategy
+		scenario.Aliens.Count = config.Aliens
+		scenario.Seed = &seed
+		result, err := scenario.RunBatch(w.Runs, w.Parallel)
+		if err != nil {
+			return fmt.Errorf("%s: %s", config, err)
+		}
+		done++
+		if err = emit(result.sweepRow(config, seed), done, len(configs)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sweepRow sums up the batch as a row of a sweep table
+func (r *BatchResult) sweepRow(config SweepConfig, seed int64) SweepRow {
+	row := SweepRow{SweepConfig: config, Runs: len(r.Runs), Seed: seed, Ticks: r.Ticks, CasualtyRate: r.CasualtyRate}
+	destroyed := make([]float64, len(r.Runs))
+	for i, run := range r.Runs {
+		destroyed[i] = float64(run.CitiesDestroyed)
+	}
+	row.CitiesDestroyed = newDistribution(destroyed)
+	if len(r.cities) > 0 {
+		// In order of the map, so the sum is always rounded the same way
+		for _, name := range r.cities {
+			row.Survival += r.Survival[name]
+		}
+		row.Survival /= float64(len(r.cities))
+	}
+	return row
+}
+
+// ParseCounts parses alien counts like `1..50`, `5..50/5` (every 5) or `1,2,5,10`, or a mix of them like `1..4,10`
+func ParseCounts(s string) ([]int, error) {
+	var counts []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		from, rest, isRange := strings.Cut(part, "..")
+		if !isRange {
+			count, err := strconv.Atoi(part)
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("%s: invalid count %s", s, part)
+			}
+			counts = append(counts, count)
+			continue
+		}
+		to, step, stepped := strings.Cut(rest, "/")
+		numbers := []int{0, 0, 1}
+		texts := []string{from, to}
+		if stepped {
+			texts = append(texts, step)
+		}
+		for i, text := range texts {
+			number, err := strconv.Atoi(strings.TrimSpace(text))
+			if err != nil || number < 0 {
+				return nil, fmt.Errorf("%s: invalid number %s", s, text)
+			}
+			numbers[i] = number
+		}
+		if numbers[2] < 1 || numbers[0] > numbers[1] {
+			return nil, fmt.Errorf("%s: range %s should go up, by at least 1", s, part)
+		}
+		for count := numbers[0]; count <= numbers[1]; count += numbers[2] {
+			counts = append(counts, count)
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,160 @@
+package alien_invastion
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseCounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []int
+		wantErr bool
+	}{
+		{name: "Count", s: "4", want: []int{4}},
+		{name: "List", s: "1,2, 5,10", want: []int{1, 2, 5, 10}},
+		{name: "Range", s: "1..5", want: []int{1, 2, 3, 4, 5}},
+		{name: "Stepped range", s: "5..20/5", want: []int{5, 10, 15, 20}},
+		{name: "Mix", s: "1..3,10", want: []int{1, 2, 3, 10}},
+		{name: "Not a number", s: "1,a", wantErr: true},
+		{name: "Negative", s: "-1", wantErr: true},
+		{name: "Going down", s: "5..1", wantErr: true},
+		{name: "Zero step", s: "1..5/0", wantErr: true},
+		{name: "Empty", s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCounts(tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// sweepTable runs the sweep from progress and returns rows emitted as a sweep table, with columns if fresh
+func sweepTable(t *testing.T, sweep Sweep, progress SweepProgress, fresh bool) (string, []int) {
+	var out bytes.Buffer
+	writer := csv.NewWriter(&out)
+	if fresh {
+		assert.NoError(t, writer.Write(SweepColumns))
+	}
+	var done []int
+	err := sweep.Run(batchScenario(0), progress, func(row SweepRow, finished, total int) error {
+		assert.Equal(t, len(sweep.Configs()), total)
+		done = append(done, finished)
+		return writer.Write(row.Record())
+	})
+	assert.NoError(t, err)
+	writer.Flush()
+	return out.String(), done
+}
+
+func TestSweep_Run(t *testing.T) {
+	sweep := Sweep{
+		Maps:       []string{"test_resources/sample_map.txt"},
+		Strategies: []string{"random", "hunt"},
+		Aliens:     []int{1, 2, 4},
+		Runs:       10,
+		Parallel:   2,
+	}
+	table, done := sweepTable(t, sweep, SweepProgress{}, true)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, done)
+	records, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, SweepColumns, records[0])
+	assert.Equal(t, 7, len(records))
+
+	// Every row is the batch of its configuration
+	for i, config := range sweep.Configs() {
+		s := batchScenario(config.Aliens)
+		s.Aliens.Strategy = config.Strategy
+		result, err := s.RunBatch(10, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, result.sweepRow(config, 100).Record(), records[i+1])
+		assert.Equal(t, []string{config.Map, config.Strategy}, records[i+1][:2])
+		assert.Equal(t, "100", records[i+1][4])
+	}
+
+	// An interrupted sweep resumes where it stopped, and ends like a sweep never interrupted
+	lines := strings.SplitAfter(table, "\n")
+	progress, err := ReadSweepProgress(strings.NewReader(strings.Join(lines[:3], "")))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(progress.Done))
+	assert.Equal(t, int64(100), *progress.Seed)
+	rest, done := sweepTable(t, sweep, progress, false)
+	assert.Equal(t, []int{3, 4, 5, 6}, done)
+	assert.Equal(t, table, strings.Join(lines[:3], "")+rest)
+}
+
+func TestSweep_RunErrors(t *testing.T) {
+	sweep := Sweep{Maps: []string{"test_resources/sample_map.txt"}, Strategies: []string{"random"}, Aliens: []int{2, 100}, Runs: 3}
+	var rows []SweepRow
+	err := sweep.Run(batchScenario(0), SweepProgress{}, func(row SweepRow, done, total int) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "aliens=100")
+	}
+	assert.Equal(t, 1, len(rows))
+
+	// A sweep resumes with the seed and runs it started with
+	other, same := int64(7), int64(100)
+	for _, progress := range []SweepProgress{{Runs: 3, Seed: &other}, {Runs: 5, Seed: &same}} {
+		err = sweep.Run(batchScenario(0), progress, func(SweepRow, int, int) error { return nil })
+		assert.Error(t, err)
+	}
+}
+
+func TestReadSweepProgress(t *testing.T) {
+	columns := strings.Join(SweepColumns, ",") + "\n"
+	tests := []struct {
+		name    string
+		table   string
+		cut     string // Part of a row left by an interrupted sweep
+		want    SweepProgress
+		wantErr bool
+	}{
+		{name: "Empty", want: SweepProgress{Done: map[SweepConfig]bool{}}},
+		{name: "Only columns", table: columns, want: SweepProgress{Done: map[SweepConfig]bool{}}},
+		{
+			name:  "Rows",
+			table: columns + "a.txt,random,1,10,5,1,0,0,0,3,3,3,0,0,0\na.txt,hunt,2,10,5,1,0,0,0,3,3,3,0,0,0\n",
+			want: SweepProgress{
+				Done: map[SweepConfig]bool{{Map: "a.txt", Strategy: "random", Aliens: 1}: true, {Map: "a.txt", Strategy: "hunt", Aliens: 2}: true},
+				Runs: 10,
+			},
+		},
+		{
+			name:  "Cut row",
+			table: columns + "a.txt,random,1,10,5,1,0,0,0,3,3,3,0,0,0\n",
+			cut:   "a.txt,hunt,2,10,5,1,0",
+			want:  SweepProgress{Done: map[SweepConfig]bool{{Map: "a.txt", Strategy: "random", Aliens: 1}: true}, Runs: 10},
+		},
+		{name: "Cut columns", cut: "map,strategy,ali", want: SweepProgress{Done: map[SweepConfig]bool{}}},
+		{name: "Not a sweep table", table: "metric,key,value\nruns,,10\n", wantErr: true},
+		{name: "Invalid count", table: columns + "a.txt,random,x,10,5,1,0,0,0,3,3,3,0,0,0\n", wantErr: true},
+		{name: "Seeds don't agree", table: columns + "a.txt,random,1,10,5,1,0,0,0,3,3,3,0,0,0\na.txt,hunt,2,10,6,1,0,0,0,3,3,3,0,0,0\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadSweepProgress(strings.NewReader(tt.table + tt.cut))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Done, got.Done)
+			assert.Equal(t, tt.want.Runs, got.Runs)
+			assert.Equal(t, int64(len(tt.table)), got.Size)
+		})
+	}
+}
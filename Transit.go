@@ -0,0 +1,79 @@
+package alien_invastion
+
+// transit is an alien walking on a road longer than one tick
+type transit struct {
+	alien    *Alien
+	road     *Road
+	from     *City
+	to       *City
+	progress int // ticks walked on the road
+}
+
+// depart puts an alien of a city on a long road, it will arrive after Road.TravelTime ticks
+func (m *GameMap) depart(alien *Alien, from *City, exit Exit) {
+	if from.Exists == false || !from.leave(alien) {
+		return
+	}
+	t := &transit{alien: alien, road: exit.Road, from: from, to: exit.To}
+	if s := from.shard; s != nil {
+		s.departed = append(s.departed, t)
+		s.leftAt = append(s.leftAt, s.turn)
+		return
+	}
+	m.transits = append(m.transits, t)
+}
+
+// updateTransits walks all aliens on roads by one tick, resolves head-on encounters, and lets aliens at the end of road arrive
+func (m *GameMap) updateTransits() (willContinue bool) {
+	willContinue = true
+	for _, t := range m.transits {
+		t.progress++
+		if t.progress < t.road.TravelTime() {
+			// Arriving tick is counted by next move
+			t.alien.Steps++
+		}
+		if t.alien.Steps > m.maxSteps {
+			willContinue = false
+		}
+	}
+
+	// Two rivals walking toward each other on same road meet once they have walked the whole road together.
+	// They fight and destroy the road, just like they destroy a city.
+	transits := m.transits
+	for i, t := range transits {
+		for _, other := range transits[i+1:] {
+			if t.alien.Alive && other.alien.Alive && t.alien.IsRival(other.alien) && t.road == other.road && t.from == other.to && t.progress+other.progress >= t.road.TravelTime() {
+				t.alien.Alive = false
+				other.alien.Alive = false
+				m.destroyRoad(t.road, t.alien, other.alien)
+			}
+		}
+	}
+
+	var walking []*transit
+	for _, t := range m.transits {
+		switch {
+		case !t.alien.Alive:
+			continue
+		case t.progress >= t.road.TravelTime():
+			if !t.to.alienArrive(t.alien) {
+				// Retreated, waiting at the end of road and try again next tick
+				t.alien.Steps++
+				walking = append(walking, t)
+			}
+		default:
+			walking = append(walking, t)
+		}
+	}
+	m.transits = walking
+	return
+}
+
+// InTransit returns aliens walking on roads at the moment
+func (m *GameMap) InTransit() []*Alien {
+	var aliens []*Alien
+	for _, t := range m.transits {
+		aliens = append(aliens, t.alien)
+	}
+	return aliens
+}
@@ -0,0 +1,123 @@
+package alien_invastion
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGameMap_UpdateWithLongRoad(t *testing.T) {
+	parser := StreamParser{}
+	m, errs := parser.ParseString("Foo east=Bar:3")
+	assert.Empty(t, errs)
+	alien := &Alien{Number: 0, Alive: true}
+	m.cities["Foo"].Aliens = []*Alien{alien}
+
+	// Departs at first tick
+	assert.True(t, m.Update())
+	assert.Empty(t, m.cities["Foo"].Aliens)
+	assert.Empty(t, m.cities["Bar"].Aliens)
+	assert.Equal(t, []*Alien{alien}, m.InTransit())
+
+	// Still walking
+	assert.True(t, m.Update())
+	assert.True(t, m.Update())
+	assert.Equal(t, 1, len(m.InTransit()))
+	assert.Equal(t, 3, alien.Steps)
+
+	// Arrives and walks back at once
+	assert.True(t, m.Update())
+	assert.Equal(t, 4, alien.Steps)
+	assert.Equal(t, 4, m.Tick())
+	assert.Equal(t, []*Alien{alien}, m.InTransit())
+	assert.Equal(t, m.cities["Foo"], m.transits[0].to)
+}
+
+func TestGameMap_updateTransits(t *testing.T) {
+	tests := []struct {
+		name          string
+		progress      []int
+		reverse       []bool
+		wantAlive     []bool
+		wantInTransit int
+		wantArrived   bool
+	}{
+		{
+			name:          "Single alien keeps walking",
+			progress:      []int{1},
+			reverse:       []bool{false},
+			wantAlive:     []bool{true},
+			wantInTransit: 1,
+		},
+		{
+			name:          "Single alien arrives",
+			progress:      []int{3},
+			reverse:       []bool{false},
+			wantAlive:     []bool{true},
+			wantInTransit: 0,
+			wantArrived:   true,
+		},
+		{
+			name:          "Head-on encounter",
+			progress:      []int{2, 1},
+			reverse:       []bool{false, true},
+			wantAlive:     []bool{false, false},
+			wantInTransit: 0,
+		},
+		{
+			name:          "Not met yet",
+			progress:      []int{1, 0},
+			reverse:       []bool{false, true},
+			wantAlive:     []bool{true, true},
+			wantInTransit: 2,
+		},
+		{
+			name:          "Same way never meets",
+			progress:      []int{2, 1},
+			reverse:       []bool{false, false},
+			wantAlive:     []bool{true, true},
+			wantInTransit: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := (&StreamParser{}).ParseString("Foo east=Bar:4")
+			foo, bar := m.cities["Foo"], m.cities["Bar"]
+			road := foo.Roads[0]
+			var aliens []*Alien
+			for i, progress := range tt.progress {
+				alien := &Alien{Number: i, Alive: true}
+				aliens = append(aliens, alien)
+				from, to := foo, bar
+				if tt.reverse[i] {
+					from, to = bar, foo
+				}
+				m.transits = append(m.transits, &transit{alien: alien, road: road, from: from, to: to, progress: progress})
+			}
+			assert.True(t, m.updateTransits())
+			for i, alien := range aliens {
+				assert.Equal(t, tt.wantAlive[i], alien.Alive)
+			}
+			assert.Equal(t, tt.wantInTransit, len(m.InTransit()))
+			assert.Equal(t, tt.wantArrived, len(bar.Aliens) > 0)
+			// Aliens fighting on a road destroy it
+			assert.Equal(t, !tt.wantAlive[0], road.Destroyed)
+		})
+	}
+}
+
+func TestGameMap_updateTransitsStepLimit(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar:4")
+	alien := &Alien{Number: 0, Steps: 10000, Alive: true}
+	m.transits = []*transit{{alien: alien, road: m.cities["Foo"].Roads[0], from: m.cities["Foo"], to: m.cities["Bar"]}}
+	assert.False(t, m.updateTransits())
+}
+
+func TestGameMap_UpdateWithoutAliens(t *testing.T) {
+	m, _ := (&StreamParser{}).ParseString("Foo east=Bar:4")
+	m.transits = []*transit{
+		{alien: &Alien{Number: 0, Alive: true}, road: m.cities["Foo"].Roads[0], from: m.cities["Foo"], to: m.cities["Bar"], progress: 2},
+		{alien: &Alien{Number: 1, Alive: true}, road: m.cities["Foo"].Roads[0], from: m.cities["Bar"], to: m.cities["Foo"], progress: 1},
+	}
+	// Both aliens die head-on, nothing left to update
+	assert.False(t, m.Update())
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	alien_invastion "alien-invastion"
+	"fmt"
+	"github.com/spf13/cobra"
+	"runtime"
+)
+
+// batchCmd plays the same game many times, and sums up how games end
+var batchCmd = &cobra.Command{
+	Use:   "batch <mapfile path> <alien count> | batch --scenario <scenario file>",
+	Short: "Play the invasion many times, and sum up how games end",
+	Long: `Play the invasion many times with different seeds, in parallel, and sum up how games end :
+survival probability of every city, games by number of cities destroyed, ticks to the end and alien casualty rates.
+Game i is seeded with --seed plus i, so a batch with the same seed always ends the same way. The game is given
+by a map file and alien count with flags of the game, or by a scenario file.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scenario, err := batchScenario(cmd, args)
+		if err != nil {
+			return err
+		}
+		runs, _ := cmd.Flags().GetInt("runs")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "csv" && format != "json" {
+			return fmt.Errorf("unknown format %s", format)
+		}
+		result, err := scenario.RunBatch(runs, parallel)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			return result.WriteJSON(cmd.OutOrStdout())
+		}
+		return result.WriteCSV(cmd.OutOrStdout())
+	},
+}
+
+// batchScenario describes the game played by batch commands, from the scenario file if given, or from args and flags
+func batchScenario(cmd *cobra.Command, args []string) (*alien_invastion.Scenario, error) {
+	path, _ := cmd.Flags().GetString("scenario")
+	if path == "" {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("map file and alien count, or a scenario file, are needed")
+		}
+		return scenarioFromFlags(cmd, args)
+	}
+	scenario, err := alien_invastion.LoadScenario(path)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Flags().Changed("seed") {
+		seed, _ := cmd.Flags().GetInt64("seed")
+		scenario.Seed = &seed
+	}
+	return scenario, nil
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().String("scenario", "", "Scenario file to play, instead of map file and alien count")
+	batchCmd.Flags().Int("runs", 100, "Games to play")
+	batchCmd.Flags().Int("parallel", runtime.NumCPU(), "Games played at the same time")
+	batchCmd.Flags().String("format", "csv", "Output format : csv (metric,key,value) or json (with every game)")
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	alien_invastion "alien-invastion"
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"strconv"
+	"strings"
+)
+
+// playCmd lets a human control defenders, or one alien, turn by turn
+var playCmd = &cobra.Command{
+	Use:   "play <mapfile path> <alien count>",
+	Short: "Play the invasion, controlling the defenders or one alien",
+	Long: `Play the invasion turn by turn. Every tick the map is shown, and for every unit you control, legal moves are listed.
+Answer with the number of the move, a direction or road name, or a city name. An empty line or "stay" keeps the unit in the city,
+and "quit" keeps all your units in their cities until the game ends. Other aliens act by themselves, and your score is shown at the end.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return cmd.Help()
+		}
+		scenario, err := scenarioFromFlags(cmd, args)
+		if err != nil {
+			return err
+		}
+		g, err := scenario.NewGame()
+		if err != nil {
+			return err
+		}
+		g.Map.OnEvent(func(e alien_invastion.Event) {
+			fmt.Println(e)
+		})
+
+		control, _ := cmd.Flags().GetString("control")
+		var units []*alien_invastion.Alien
+		switch control {
+		case "defenders":
+			units = g.Defenders
+		case "alien":
+			if len(g.Aliens) > 0 {
+				units = g.Aliens[:1]
+			}
+		default:
+			return fmt.Errorf("unknown side to control %s", control)
+		}
+		if len(units) == 0 {
+			return fmt.Errorf("nothing to control, place some %s first", control)
+		}
+
+		p := &player{game: g, input: bufio.NewScanner(cmd.InOrStdin()), shownTick: -1}
+		for _, unit := range units {
+			unit.Strategy = alien_invastion.Player{Decide: p.decide}
+		}
+		g.Run()
+		printResult(g)
+		fmt.Printf("Score : %d\n", score(g, control, units))
+		return nil
+	},
+}
+
+// player reads moves of the human from input
+type player struct {
+	game      *alien_invastion.Game
+	input     *bufio.Scanner
+	shownTick int
+	quit      bool
+}
+
+// decide shows the map once per tick, lists legal moves of the unit and reads the choice, until a valid one is given
+func (p *player) decide(unit *alien_invastion.Alien, from *alien_invastion.City, exits []alien_invastion.Exit) (alien_invastion.Exit, bool) {
+	if p.quit {
+		return alien_invastion.Exit{}, false
+	}
+	if p.shownTick != p.game.Map.Tick() {
+		p.shownTick = p.game.Map.Tick()
+		fmt.Printf("=== Tick %d ===\n%s\n", p.shownTick, p.game.Map.DumpMap())
+		for _, city := range p.game.Map.Cities() {
+			if len(city.Aliens) > 0 {
+				fmt.Printf("%s : %s\n", city.Name, describeUnits(p.game.Map.AlienIDs(), city.Aliens))
+			}
+		}
+	}
+
+	for {
+		fmt.Printf("%s in %s, where to go?\n  0) stay\n", describeUnits(p.game.Map.AlienIDs(), []*alien_invastion.Alien{unit}), from.Name)
+		for i, exit := range exits {
+			fmt.Printf("  %d) %s\n", i+1, exit)
+		}
+		if !p.input.Scan() {
+			// Input is over, the units stay until the game ends
+			p.quit = true
+			return alien_invastion.Exit{}, false
+		}
+		answer := strings.TrimSpace(p.input.Text())
+		switch answer {
+		case "", "0", "stay":
+			return alien_invastion.Exit{}, false
+		case "quit":
+			p.quit = true
+			return alien_invastion.Exit{}, false
+		}
+		if exit, found := findExit(exits, answer); found {
+			return exit, true
+		}
+		fmt.Printf("Unknown move %s\n", answer)
+	}
+}
+
+// findExit finds the exit by its number in the list, label or the city it leads to
+func findExit(exits []alien_invastion.Exit, answer string) (alien_invastion.Exit, bool) {
+	if number, err := strconv.Atoi(answer); err == nil {
+		if number >= 1 && number <= len(exits) {
+			return exits[number-1], true
+		}
+		return alien_invastion.Exit{}, false
+	}
+	for _, exit := range exits {
+		if strings.EqualFold(exit.Label(), answer) || strings.EqualFold(exit.To.Name, answer) {
+			return exit, true
+		}
+	}
+	return alien_invastion.Exit{}, false
+}
+
+// describeUnits prints units like `alien 1, defender 5`, by their names if they are named
+func describeUnits(ids *alien_invastion.AlienIDs, units []*alien_invastion.Alien) string {
+	var names []string
+	for _, unit := range units {
+		kind := "alien"
+		if unit.Side == alien_invastion.Defenders {
+			kind = "defender"
+		}
+		names = append(names, fmt.Sprintf("%s %s", kind, ids.Name(unit.Number)))
+	}
+	return strings.Join(names, ", ")
+}
+
+// score rewards the human. Defenders earn 10 for every surviving city and 5 for every alien killed.
+// An alien earns 10 for every city destroyed, and 1 for every step it walked.
+func score(g *alien_invastion.Game, control string, units []*alien_invastion.Alien) int {
+	cities := len(g.Map.Cities())
+	survived := g.Map.ExistCityCount()
+	if control == "defenders" {
+		killed := 0
+		for _, alien := range g.Aliens {
+			if !alien.Alive {
+				killed++
+			}
+		}
+		return survived*10 + killed*5
+	}
+	return (cities-survived)*10 + units[0].Steps
+}
+
+func init() {
+	rootCmd.AddCommand(playCmd)
+	playCmd.Flags().String("control", "defenders", "Side you control : defenders, or alien for the first alien")
+}
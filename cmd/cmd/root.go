@@ -27,6 +27,7 @@ import (
 	"github.com/spf13/cobra"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -37,39 +38,117 @@ var rootCmd = &cobra.Command{
 Roles are : 
 1. Alien will enter a random city
 2. Alien will try to enter an adjacent city
-3. When 2 aliens enters same city, they will fight, and result the city being destroyed.
-4. If city is destroyed, all path lead to, and leads from this city, will be removed, preventing other aliens from entering or exiting.`,
+3. When 2 aliens enters same city, they will fight and kill each other, and result the city being destroyed (fortified cities may survive several fights).
+4. If city is destroyed, all path lead to, and leads from this city, will be removed, preventing other aliens from entering or exiting.
+5. Human defenders, if any, kill or repel aliens on contact and protect cities. The side left standing wins.`,
+	// Map file and alien count, not a sub command
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 2 {
 			return cmd.Help()
 		}
-		parser := alien_invastion.StreamParser{}
-		gameMap, errors := parser.ParseFile(args[0])
-		if errors != nil && len(errors) > 0 {
-			return fmt.Errorf("%v", errors)
-		}
-		alienCount, err := strconv.Atoi(args[1])
+		scenario, err := scenarioFromFlags(cmd, args)
 		if err != nil {
 			return err
 		}
-		aliens := make([]*alien_invastion.Alien, 0)
-		for i := 0; i < alienCount; i++ {
-			aliens = append(aliens, alien_invastion.NewAlien())
-		}
-		err = gameMap.AssignAliens(aliens)
+		g, err := scenario.NewGame()
 		if err != nil {
 			return err
 		}
-		for {
-			if gameMap.Update() == false {
-				break
-			}
-		}
-		fmt.Println(gameMap.DumpMap())
+		runGame(g)
 		return nil
 	},
 }
 
+// scenarioFromFlags describes the game given by command line flags, args are map file path and alien count
+func scenarioFromFlags(cmd *cobra.Command, args []string) (*alien_invastion.Scenario, error) {
+	s := alien_invastion.NewScenario()
+	s.Map = args[0]
+	alienCount, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, err
+	}
+	s.Aliens.Count = alienCount
+	return s, applyFlags(cmd, s)
+}
+
+// applyFlags sets rules, aliens, defenders and output of the scenario from flags of the game
+func applyFlags(cmd *cobra.Command, s *alien_invastion.Scenario) error {
+	s.Directions, _ = cmd.Flags().GetString("directions")
+	s.Graph, _ = cmd.Flags().GetBool("graph")
+	s.Compact, _ = cmd.Flags().GetBool("compact")
+	s.Workers, _ = cmd.Flags().GetInt("workers")
+	s.Actors, _ = cmd.Flags().GetBool("actors")
+	if cmd.Flags().Changed("seed") {
+		seed, _ := cmd.Flags().GetInt64("seed")
+		s.Seed = &seed
+	}
+	s.Aliens.Strength, _ = cmd.Flags().GetInt("alien-strength")
+	s.Aliens.Health, _ = cmd.Flags().GetInt("alien-health")
+	s.Aliens.Factions, _ = cmd.Flags().GetStringSlice("factions")
+	s.Aliens.Stack, _ = cmd.Flags().GetBool("stack")
+	s.Aliens.Placement, _ = cmd.Flags().GetString("placement")
+	s.Aliens.Names, _ = cmd.Flags().GetStringSlice("alien-names")
+	place, _ := cmd.Flags().GetStringToString("place")
+	for index, city := range place {
+		alien, err := strconv.Atoi(index)
+		if err != nil {
+			return fmt.Errorf("invalid alien %s to place at %s", index, city)
+		}
+		if s.Aliens.At == nil {
+			s.Aliens.At = make(map[int]string)
+		}
+		s.Aliens.At[alien] = city
+	}
+	s.Defenders.Count, _ = cmd.Flags().GetInt("defenders")
+	s.Defenders.Strategy, _ = cmd.Flags().GetString("defender-strategy")
+	s.Rules.Combat, _ = cmd.Flags().GetString("combat")
+	s.Rules.RetreatChance, _ = cmd.Flags().GetFloat64("retreat-chance")
+	s.Rules.SameFaction, _ = cmd.Flags().GetString("same-faction")
+	s.Rules.Capacity, _ = cmd.Flags().GetInt("capacity")
+	s.Rules.Gather, _ = cmd.Flags().GetInt("gather")
+	s.Rules.RoadFailureRate, _ = cmd.Flags().GetFloat64("road-failure-rate")
+	s.Rules.RebuildDelay, _ = cmd.Flags().GetInt("rebuild-delay")
+	s.Rules.Fatigue.Stamina, _ = cmd.Flags().GetInt("stamina")
+	s.Rules.Fatigue.MoveCost, _ = cmd.Flags().GetInt("move-cost")
+	s.Rules.Fatigue.Rest, _ = cmd.Flags().GetInt("rest")
+	s.Rules.Fatigue.Lifespan, _ = cmd.Flags().GetInt("lifespan")
+	s.Rules.Fatigue.Exhaustion, _ = cmd.Flags().GetString("exhaustion")
+	s.Spawn, _ = cmd.Flags().GetStringArray("spawn")
+	s.Output.Stats, _ = cmd.Flags().GetBool("stats")
+	return nil
+}
+
+// runGame runs the game to the end, and prints what its scenario asks for
+func runGame(g *alien_invastion.Game) {
+	if g.Scenario.Output.Events {
+		g.Map.OnEvent(func(e alien_invastion.Event) {
+			fmt.Println(e)
+		})
+	}
+	g.Run()
+	printResult(g)
+}
+
+// printResult prints the map left, faction control and the winner if any, and stats, as the scenario asks
+func printResult(g *alien_invastion.Game) {
+	if g.Scenario.Output.Map {
+		fmt.Println(g.Map.DumpMap())
+	}
+	if factions := g.Scenario.Aliens.Factions; len(factions) > 0 {
+		control := g.Map.FactionControl()
+		for _, faction := range factions {
+			fmt.Printf("Faction %s controls %d cities : %s\n", faction, len(control[faction]), strings.Join(control[faction], ", "))
+		}
+	}
+	if len(g.Defenders) > 0 {
+		fmt.Printf("Winner : %s\n", g.Map.Winner())
+	}
+	if g.Scenario.Output.Stats {
+		fmt.Printf("Stats : %s\n", g.Map.Stats())
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -89,4 +168,33 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	// rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().StringP("directions", "d", "compass", "Direction set of the map : compass, 8way, compass3d or 8way3d")
+	rootCmd.PersistentFlags().BoolP("graph", "g", false, "Read the map as a graph map, lines look like 'Foo -> Bar, highway=Baz'")
+	rootCmd.PersistentFlags().Bool("compact", false, "Keep the map in compact storage, for maps with millions of cities")
+	rootCmd.PersistentFlags().Int("workers", 0, "Goroutines moving aliens in every tick, plays exactly like the classic engine (0, the default) with the same seed")
+	rootCmd.PersistentFlags().Bool("actors", false, "Run every alien and every city as a goroutine of its own, plays like the classic engine")
+	rootCmd.PersistentFlags().Float64("road-failure-rate", 0, "Chance of each road being destroyed in every tick")
+	rootCmd.PersistentFlags().Int64("seed", 0, "Seed of the simulation, same seed with same map always ends in the same way (random if not set)")
+	rootCmd.PersistentFlags().String("combat", "mutual", "How fights end : mutual (both aliens die) or strength (stronger alien more likely wins)")
+	rootCmd.PersistentFlags().Float64("retreat-chance", 0, "Chance of an alien retreating instead of fighting, for strength combat")
+	rootCmd.PersistentFlags().Int("alien-strength", 1, "Strength of every alien")
+	rootCmd.PersistentFlags().Int("alien-health", 1, "Health of every alien")
+	rootCmd.PersistentFlags().StringSlice("alien-names", nil, "Names of units in events by their numbers, like Zorg,Blip for units 0 and 1, the others go by their numbers")
+	rootCmd.PersistentFlags().StringSlice("factions", nil, "Factions aliens join in turn, like red,blue. Aliens of rival factions fight, aliens without faction fight everyone")
+	rootCmd.PersistentFlags().String("same-faction", "coexist", "What happens when aliens of same faction meet : coexist or merge")
+	rootCmd.PersistentFlags().Int("capacity", 1, "How many aliens a city can hold, unless the city has its own capacity (0 means unlimited)")
+	rootCmd.PersistentFlags().Int("gather", 0, "Destroy a city once this number of aliens gather in it (0 to disable)")
+	rootCmd.PersistentFlags().Bool("stack", false, "Allow more than one alien in a city when placing aliens, up to its capacity")
+	rootCmd.PersistentFlags().String("placement", "uniform", "How aliens are placed : uniform, clustered, spread (far from each other), border (cities with fewest roads) or weighted:hp, weighted:defense, weighted:roads, weighted:defenders")
+	rootCmd.PersistentFlags().StringToString("place", nil, "Cities of aliens by their index, like 0=Akel,1=Delmon, the others are placed by the placement strategy")
+	rootCmd.PersistentFlags().Int("defenders", 0, "Human defenders placed in random cities, besides garrisons declared by the map like 'Foo defenders=2'")
+	rootCmd.PersistentFlags().Int("rebuild-delay", 0, "Ticks after which a destroyed city is rebuilt with its roads, while no alien is around (0 to disable)")
+	rootCmd.PersistentFlags().Int("stamina", 0, "Fatigue an alien takes before being exhausted (0 means aliens never tire)")
+	rootCmd.PersistentFlags().Int("move-cost", 1, "Fatigue of every move")
+	rootCmd.PersistentFlags().Int("rest", 1, "Fatigue recovered in every tick an alien stays in a city")
+	rootCmd.PersistentFlags().Int("lifespan", 0, "Steps an alien lives (0 means forever)")
+	rootCmd.PersistentFlags().String("exhaustion", "stay", "What happens to an exhausted alien : stay (until rested) or die")
+	rootCmd.PersistentFlags().Bool("stats", false, "Print stats of the game at the end")
+	rootCmd.PersistentFlags().StringArray("spawn", nil, "Wave of aliens landing later, like 3@100 (3 aliens at tick 100), 3@100+50x4 (then every 50 ticks, 4 times in total) or 2@10:Foo|Bar (landing at Foo or Bar), may be repeated")
+	rootCmd.PersistentFlags().String("defender-strategy", "guard", "How defenders move : guard (stay in the city), hunt (attack aliens in neighbor cities) or random")
 }
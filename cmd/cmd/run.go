@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	alien_invastion "alien-invastion"
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+// runCmd runs a game described by a scenario file
+var runCmd = &cobra.Command{
+	Use:   "run --scenario <scenario file>",
+	Short: "Run the invasion described by a scenario file",
+	Long: `Run the invasion described by a scenario file, YAML or JSON if the file ends with .json.
+A scenario bundles the map, seed, aliens and their placements, defenders, rules, waves, termination rules and output settings,
+see test_resources/scenario.yaml. Flags of the game are ignored, but --seed overrides the seed of the scenario.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("scenario")
+		if path == "" {
+			return fmt.Errorf("no scenario file given")
+		}
+		scenario, err := alien_invastion.LoadScenario(path)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("seed") {
+			seed, _ := cmd.Flags().GetInt64("seed")
+			scenario.Seed = &seed
+		}
+		g, err := scenario.NewGame()
+		if err != nil {
+			return err
+		}
+		runGame(g)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().String("scenario", "", "Scenario file to run")
+}
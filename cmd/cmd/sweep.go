@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	alien_invastion "alien-invastion"
+	"encoding/csv"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"runtime"
+)
+
+// sweepCmd plays batches over a grid of maps, movement strategies and alien counts, and writes a table of their outcomes
+var sweepCmd = &cobra.Command{
+	Use:   "sweep --aliens <counts> [--maps <mapfile paths>] [--strategies <strategies>] [--output <file>]",
+	Short: "Play batches over alien counts, movement strategies and maps, and write a table of outcomes",
+	Long: `Play a batch of games for every configuration of map, movement strategy and alien count, and write
+a CSV table with one row per configuration : share of cities surviving, and mean, median and 90th percentile
+of cities destroyed, ticks and alien casualty rate. Every configuration uses the same seeds, see batch.
+Other rules come from flags of the game, or from a scenario file, which also gives the default map and strategy.
+Progress is reported on stderr. With --output, rows are written as soon as configurations are done, and
+running the same sweep again on the file resumes it, skipping configurations already in the table.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base := alien_invastion.NewScenario()
+		if path, _ := cmd.Flags().GetString("scenario"); path != "" {
+			var err error
+			if base, err = alien_invastion.LoadScenario(path); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("seed") {
+				seed, _ := cmd.Flags().GetInt64("seed")
+				base.Seed = &seed
+			}
+		} else if err := applyFlags(cmd, base); err != nil {
+			return err
+		}
+
+		counts, _ := cmd.Flags().GetString("aliens")
+		aliens, err := alien_invastion.ParseCounts(counts)
+		if err != nil {
+			return err
+		}
+		sweep := alien_invastion.Sweep{Aliens: aliens}
+		sweep.Maps, _ = cmd.Flags().GetStringSlice("maps")
+		sweep.Strategies, _ = cmd.Flags().GetStringSlice("strategies")
+		sweep.Runs, _ = cmd.Flags().GetInt("runs")
+		sweep.Parallel, _ = cmd.Flags().GetInt("parallel")
+		if len(sweep.Maps) == 0 {
+			if base.Map == "" {
+				return fmt.Errorf("maps to sweep, or a scenario file with a map, are needed")
+			}
+			sweep.Maps = []string{base.Map}
+		}
+		if len(sweep.Strategies) == 0 {
+			sweep.Strategies = []string{base.Aliens.Strategy}
+		}
+		for _, strategy := range sweep.Strategies {
+			if _, err = alien_invastion.MovementStrategyFromString(strategy); err != nil {
+				return err
+			}
+		}
+
+		out, progress, empty, err := sweepOutput(cmd)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		writer := csv.NewWriter(out)
+		if empty {
+			writer.Write(alien_invastion.SweepColumns)
+		}
+		return sweep.Run(base, progress, func(row alien_invastion.SweepRow, done, total int) error {
+			writer.Write(row.Record())
+			writer.Flush()
+			fmt.Fprintf(cmd.ErrOrStderr(), "%d/%d %s\n", done, total, row.SweepConfig)
+			return writer.Error()
+		})
+	},
+}
+
+// sweepOutput opens the table the sweep writes, stdout without --output, reads what a former sweep did in it,
+// drops the row it was writing if it was interrupted, and tells if it is empty, without columns
+func sweepOutput(cmd *cobra.Command) (*os.File, alien_invastion.SweepProgress, bool, error) {
+	path, _ := cmd.Flags().GetString("output")
+	if path == "" {
+		return os.Stdout, alien_invastion.SweepProgress{}, true, nil
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, alien_invastion.SweepProgress{}, false, err
+	}
+	progress, err := alien_invastion.ReadSweepProgress(file)
+	if err != nil {
+		file.Close()
+		return nil, progress, false, fmt.Errorf("%s: %s", path, err)
+	}
+	if err = file.Truncate(progress.Size); err != nil {
+		file.Close()
+		return nil, progress, false, err
+	}
+	if len(progress.Done) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "resuming %s, %d configurations done\n", path, len(progress.Done))
+	}
+	return file, progress, progress.Size == 0, nil
+}
+
+func init() {
+	rootCmd.AddCommand(sweepCmd)
+	sweepCmd.Flags().String("scenario", "", "Scenario file giving rules, default map and strategy, instead of flags of the game")
+	sweepCmd.Flags().String("aliens", "", "Alien counts, like 1..50, 5..50/5 (every 5) or 1,2,5,10")
+	sweepCmd.Flags().StringSlice("maps", nil, "Map files, the map of the scenario by default")
+	sweepCmd.Flags().StringSlice("strategies", nil, "Movement strategies : random, guard or hunt, the strategy of the scenario by default")
+	sweepCmd.Flags().Int("runs", 100, "Games to play for every configuration")
+	sweepCmd.Flags().Int("parallel", runtime.NumCPU(), "Games played at the same time")
+	sweepCmd.Flags().String("output", "", "CSV file to write, and to resume the sweep from if it was interrupted, stdout by default")
+	_ = sweepCmd.MarkFlagRequired("aliens")
+}